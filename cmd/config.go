@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	configFileName = ".k8ship.yaml"
+)
+
+// loadConfigFiles reads ~/.k8ship.yaml and ./.k8ship.yaml in this order
+// Values in the latter file override the former
+func loadConfigFiles() error {
+	viper.SetConfigType("yaml")
+
+	paths := []string{}
+
+	if home, err := homedir.Dir(); err == nil {
+		paths = append(paths, filepath.Join(home, configFileName))
+	}
+
+	paths = append(paths, configFileName)
+
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+
+		viper.SetConfigFile(p)
+
+		if err := viper.MergeInConfig(); err != nil {
+			return errors.Wrapf(err, "failed to load config file %s", p)
+		}
+	}
+
+	if rootOpts.env != "" && !viper.IsSet(configEnvironmentKey("")) {
+		return errors.Errorf("environment %q is not defined in config file", rootOpts.env)
+	}
+
+	return nil
+}
+
+// configEnvironmentKey returns the key of the given item in the selected environment
+func configEnvironmentKey(key string) string {
+	if key == "" {
+		return "environments." + rootOpts.env
+	}
+
+	return "environments." + rootOpts.env + "." + key
+}
+
+// configBool returns the bool value in config file
+// The value in the selected environment takes precedence over the top-level value
+// nil is returned if the value is not set
+func configBool(key string) *bool {
+	var b bool
+
+	if rootOpts.env != "" && viper.IsSet(configEnvironmentKey(key)) {
+		b = viper.GetBool(configEnvironmentKey(key))
+		return &b
+	}
+
+	if viper.IsSet(key) {
+		b = viper.GetBool(key)
+		return &b
+	}
+
+	return nil
+}
+
+// configString returns the string value in config file
+// The value in the selected environment takes precedence over the top-level value
+func configString(key string) string {
+	if rootOpts.env != "" && viper.IsSet(configEnvironmentKey(key)) {
+		return viper.GetString(configEnvironmentKey(key))
+	}
+
+	return viper.GetString(key)
+}
+
+// configStringSlice returns the string list value in config file
+// The value in the selected environment takes precedence over the top-level value
+// nil is returned if the value is not set
+func configStringSlice(key string) []string {
+	if rootOpts.env != "" && viper.IsSet(configEnvironmentKey(key)) {
+		return viper.GetStringSlice(configEnvironmentKey(key))
+	}
+
+	if viper.IsSet(key) {
+		return viper.GetStringSlice(key)
+	}
+
+	return nil
+}
+
+// setFlagFromConfig sets the config value to the flag of command
+// unless the flag is given explicitly or the config value is empty
+func setFlagFromConfig(cmd *cobra.Command, name, key string) error {
+	f := cmd.Flags().Lookup(name)
+	if f == nil || f.Changed {
+		return nil
+	}
+
+	v := configString(key)
+	if v == "" {
+		return nil
+	}
+
+	if err := f.Value.Set(v); err != nil {
+		return errors.Wrapf(err, "invalid value %q of %q in config file", v, key)
+	}
+
+	return nil
+}
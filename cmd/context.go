@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errClusterSkipped = errors.New("skipped because the preceding cluster failed")
+)
+
+// kubeContexts returns the Kubernetes contexts given as comma-separated --context
+// Empty string, which means current-context in kubeconfig, is returned if no context is given
+func kubeContexts() []string {
+	contexts := []string{}
+
+	for _, c := range strings.Split(rootOpts.context, ",") {
+		if c = strings.TrimSpace(c); c != "" && !containsString(contexts, c) {
+			contexts = append(contexts, c)
+		}
+	}
+
+	if len(contexts) == 0 {
+		return []string{""}
+	}
+
+	return contexts
+}
+
+// singleKubeContext returns the Kubernetes context for the commands which handle only one cluster
+// Error is returned if multiple contexts are given
+func singleKubeContext() (string, error) {
+	contexts := kubeContexts()
+
+	if len(contexts) > 1 {
+		return "", errors.Errorf("multiple contexts %q are given, but this command accepts only one context", strings.Join(contexts, ","))
+	}
+
+	return contexts[0], nil
+}
+
+// printContextHeader writes the header of output grouped by cluster
+func printContextHeader(w io.Writer, context string) {
+	fmt.Fprintf(w, "***** context: %s *****\n", context)
+}
+
+// runClustersSequentially calls f for each cluster in order, and stops at the first failure
+// errClusterSkipped is set to the clusters after the failed one
+func runClustersSequentially(contexts []string, f func(i int, w io.Writer) error) []error {
+	errs := make([]error, len(contexts))
+
+	for i := range contexts {
+		if len(contexts) > 1 {
+			printContextHeader(os.Stdout, contexts[i])
+		}
+
+		errs[i] = f(i, os.Stdout)
+
+		if len(contexts) > 1 {
+			fmt.Printf("\n")
+		}
+
+		if errs[i] != nil {
+			for j := i + 1; j < len(contexts); j++ {
+				errs[j] = errClusterSkipped
+			}
+
+			break
+		}
+	}
+
+	return errs
+}
+
+// runClustersInParallel calls f for all clusters at the same time
+// Output of each cluster is buffered, and printed in order after all calls finish
+func runClustersInParallel(contexts []string, f func(i int, w io.Writer) error) []error {
+	bufs := make([]bytes.Buffer, len(contexts))
+
+	errs := runConcurrently(len(contexts), len(contexts), func(i int) error {
+		return f(i, &bufs[i])
+	})
+
+	for i := range contexts {
+		if len(contexts) > 1 {
+			printContextHeader(os.Stdout, contexts[i])
+		}
+
+		os.Stdout.Write(bufs[i].Bytes())
+
+		if len(contexts) > 1 {
+			fmt.Printf("\n")
+		}
+	}
+
+	return errs
+}
+
+// clusterResults prints the result table of clusters and returns error if any cluster failed
+// The error is returned as it is if there is only one cluster
+func clusterResults(contexts []string, errs []error) error {
+	if len(contexts) == 1 {
+		return errs[0]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTEXT\tRESULT\tMESSAGE")
+
+	failed := 0
+
+	for i, c := range contexts {
+		switch errs[i] {
+		case nil:
+			fmt.Fprintf(w, "%s\tsucceeded\t\n", c)
+		case errClusterSkipped:
+			failed++
+			fmt.Fprintf(w, "%s\tskipped\t%s\n", c, errs[i])
+		default:
+			failed++
+			fmt.Fprintf(w, "%s\tfailed\t%s\n", c, strings.Replace(errs[i].Error(), "\n", " ", -1))
+		}
+	}
+
+	w.Flush()
+
+	if failed > 0 {
+		return errors.Errorf("%d of %d clusters did not succeed", failed, len(contexts))
+	}
+
+	return nil
+}
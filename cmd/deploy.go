@@ -3,7 +3,10 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/dtan4/k8ship/github"
 	"github.com/dtan4/k8ship/kubernetes"
@@ -19,13 +22,33 @@ var deployCmd = &cobra.Command{
 }
 
 var deployOpts = struct {
-	accessToken string
-	dryRun      bool
-	image       string
-	namespace   string
-	ref         string
-	tag         string
-	user        string
+	accessToken         string
+	allNamespaces       bool
+	allowDowngrade      bool
+	autoRollback        bool
+	commentPRs          bool
+	concurrency         int
+	createRelease       bool
+	diff                bool
+	dryRun              bool
+	ignoreStatus        bool
+	image               string
+	logURL              string
+	namespace           string
+	pinDigest           bool
+	ref                 string
+	releaseNotes        bool
+	releaseNotesPayload bool
+	repo                string
+	selector            string
+	sequential          bool
+	skipImageCheck      bool
+	tag                 string
+	tagRelease          bool
+	timeout             time.Duration
+	user                string
+	wait                bool
+	waitForImage        time.Duration
 }{}
 
 func doDeploy(cmd *cobra.Command, args []string) error {
@@ -43,146 +66,903 @@ func doDeploy(cmd *cobra.Command, args []string) error {
 		return errors.New("--image, --tag, or ref (branch, full commit SHA-1 or short commit SHA-1) must be given")
 	}
 
-	k8sClient, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, rootOpts.context)
-	if err != nil {
-		return errors.Wrap(err, "failed to create Kubernetes client")
+	if deployOpts.ref == "" && (deployOpts.releaseNotes || deployOpts.createRelease || deployOpts.releaseNotesPayload) {
+		return errors.New("release notes can be generated only when ref is given")
 	}
 
-	deployments, err := k8sClient.ListDeployments(deployOpts.namespace)
-	if err != nil {
-		return errors.Wrap(err, "failed to retrieve Deployments")
+	if deployOpts.ref == "" && deployOpts.tagRelease {
+		return errors.New("tag can be created only when ref is given")
 	}
 
-	if len(deployments) == 0 {
-		return errors.Errorf("no Deployment found in namespace %s", deployOpts.namespace)
+	if deployOpts.concurrency < 1 {
+		return errors.New("concurrency must be greater than 0")
 	}
 
-	targetDeployments := []*kubernetes.Deployment{}
+	if deployOpts.ref != "" {
+		if err := checkCommentPullRequests(deployOpts.commentPRs, deployOpts.wait || deployOpts.autoRollback); err != nil {
+			return err
+		}
 
-	for _, d := range deployments {
-		if d.IsDeployTarget() {
-			targetDeployments = append(targetDeployments, d)
+		if err := checkTagRelease(deployOpts.tagRelease, deployOpts.wait || deployOpts.autoRollback); err != nil {
+			return err
 		}
 	}
 
-	if len(targetDeployments) == 0 {
-		return errors.New("no target Deployments found")
+	contexts := kubeContexts()
+
+	var ghClient *github.Client
+
+	if deployOpts.ref != "" {
+		ghClient = github.NewClient(context.Background(), deployOpts.accessToken)
+	}
+
+	cache := newDeployCache()
+
+	if deployOpts.sequential || len(contexts) == 1 {
+		errs := runClustersSequentially(contexts, func(i int, w io.Writer) error {
+			c, err := planClusterDeploy(contexts[i], ghClient, cache)
+			if err != nil {
+				return err
+			}
+
+			if deployOpts.dryRun {
+				return c.printDryRun(ghClient)
+			}
+
+			if err := c.start(ghClient); err != nil {
+				c.cancel(err.Error())
+				return err
+			}
+
+			err = c.execute(w)
+
+			if ferr := c.finish(ghClient); ferr != nil {
+				return ferr
+			}
+
+			return err
+		})
+
+		return clusterResults(contexts, errs)
 	}
 
-	targetContainers := map[string]*kubernetes.Container{}
+	clusters := make([]*clusterDeploy, 0, len(contexts))
 
-	for _, d := range targetDeployments {
-		c, err := d.DeployTargetContainer()
+	for _, ctx := range contexts {
+		c, err := planClusterDeploy(ctx, ghClient, cache)
 		if err != nil {
-			return errors.Wrapf(err, "failed to retrieve deploy target container of Deployment %q", d.Name())
+			return errors.Wrapf(err, "failed to prepare deploy in context %s", ctx)
 		}
 
-		targetContainers[d.Name()] = c
+		clusters = append(clusters, c)
 	}
 
-	repo, err := kubernetes.GetTargetRepository(targetDeployments, targetContainers)
+	for i, c := range clusters {
+		printContextHeader(os.Stdout, c.context)
+
+		if deployOpts.dryRun {
+			if err := c.printDryRun(ghClient); err != nil {
+				return errors.Wrapf(err, "failed to prepare deploy in context %s", c.context)
+			}
+		} else if err := c.start(ghClient); err != nil {
+			err = errors.Wrapf(err, "failed to prepare deploy in context %s", c.context)
+
+			for _, started := range clusters[:i+1] {
+				started.cancel(err.Error())
+			}
+
+			return err
+		}
+
+		fmt.Printf("\n")
+	}
+
+	if deployOpts.dryRun {
+		return nil
+	}
+
+	errs := runClustersInParallel(contexts, func(i int, w io.Writer) error {
+		return clusters[i].execute(w)
+	})
+
+	for i, c := range clusters {
+		if err := c.finish(ghClient); err != nil && errs[i] == nil {
+			errs[i] = err
+		}
+	}
+
+	return clusterResults(contexts, errs)
+}
+
+// clusterDeploy represents the deploy to one Kubernetes cluster
+type clusterDeploy struct {
+	cache         *deployCache
+	context       string
+	deploys       []*namespaceDeploy
+	k8sClient     *kubernetes.Client
+	showNamespace bool
+	succeeded     []*namespaceDeploy
+}
+
+// planClusterDeploy plans the deploy to all target namespaces in the cluster of the given context
+func planClusterDeploy(kubeContext string, ghClient *github.Client, cache *deployCache) (*clusterDeploy, error) {
+	k8sClient, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, kubeContext)
 	if err != nil {
-		return errors.Wrap(err, "failed to retrieve target repository")
+		return nil, errors.Wrap(err, "failed to create Kubernetes client")
 	}
 
-	image, err := kubernetes.GetTargetImage(targetContainers)
+	namespaces, err := targetNamespaces(k8sClient, deployOpts.namespace, deployOpts.allNamespaces, deployOpts.selector, true)
 	if err != nil {
-		return errors.Wrap(err, "failed to retrieve target image")
+		return nil, err
 	}
 
-	var newImage string
+	c := &clusterDeploy{
+		cache:         cache,
+		context:       kubeContext,
+		deploys:       make([]*namespaceDeploy, 0, len(namespaces)),
+		k8sClient:     k8sClient,
+		showNamespace: deployOpts.allNamespaces || len(namespaces) > 1,
+	}
 
-	if deployOpts.ref == "" {
-		if deployOpts.image != "" {
-			newImage = deployOpts.image
+	for _, ns := range namespaces {
+		d, err := planNamespaceDeploy(k8sClient, ghClient, ns, cache)
+		if err != nil {
+			if c.showNamespace {
+				return nil, errors.Wrapf(err, "failed to prepare deploy in namespace %s", ns)
+			}
+
+			return nil, err
+		}
+
+		c.deploys = append(c.deploys, d)
+	}
+
+	return c, nil
+}
+
+// printDryRun prints what will be done in the cluster
+func (c *clusterDeploy) printDryRun(ghClient *github.Client) error {
+	for _, d := range c.deploys {
+		if c.showNamespace {
+			printNamespaceHeader(os.Stdout, d.namespace, "[dry-run] ")
+		}
+
+		if err := d.printDryRun(ghClient, c.cache); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// start creates GitHub Deployments and prints the images to deploy
+func (c *clusterDeploy) start(ghClient *github.Client) error {
+	for _, d := range c.deploys {
+		if c.showNamespace {
+			printNamespaceHeader(os.Stdout, d.namespace, "")
+		}
+
+		if err := d.createGitHubDeployments(c.k8sClient, ghClient); err != nil {
+			return err
+		}
+
+		for _, g := range d.groups {
+			printDeployGroup(g, d.images, len(d.groups) > 1, "")
+		}
+	}
+
+	return nil
+}
+
+// cancel marks the GitHub Deployments created by start as error, because the deploy is not executed
+func (c *clusterDeploy) cancel(description string) {
+	for _, d := range c.deploys {
+		setDeployGroupsStatus(d.groups, github.DeploymentStateError, description)
+	}
+}
+
+// execute patches the target workloads and waits for their rollouts
+// Progress is written to w, because this may run concurrently with other clusters
+func (c *clusterDeploy) execute(w io.Writer) error {
+	failures := namespaceFailures{}
+
+	errs := runConcurrently(len(c.deploys), deployOpts.concurrency, func(i int) error {
+		return c.deploys[i].setImages(c.k8sClient)
+	})
+
+	for i, d := range c.deploys {
+		if errs[i] != nil {
+			setDeployGroupsStatus(d.groups, github.DeploymentStateError, errs[i].Error())
+			failures[d.namespace] = errs[i]
+			continue
 		}
 
-		if deployOpts.tag != "" {
-			newImage = image + ":" + deployOpts.tag
+		setDeployGroupsStatus(d.groups, github.DeploymentStateInProgress, "")
+	}
+
+	if len(failures) > 0 {
+		c.abort(w, failures)
+
+		return failures.err(c.showNamespace)
+	}
+
+	fmt.Fprintf(w, "\n")
+
+	if deployOpts.wait || deployOpts.autoRollback {
+		deadline := time.Now().Add(deployOpts.timeout)
+
+		for _, d := range c.deploys {
+			if c.showNamespace {
+				printNamespaceHeader(w, d.namespace, "")
+			}
+
+			if err := d.waitForRollouts(w, c.k8sClient, time.Until(deadline)); err != nil {
+				failures[d.namespace] = err
+				continue
+			}
+
+			c.succeeded = append(c.succeeded, d)
 		}
+
+		if len(failures) > 0 {
+			return failures.err(c.showNamespace)
+		}
+
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintln(w, "deployments successfully rolled out!")
+
+		return nil
+	}
+
+	// GitHub Deployments are left in_progress, and neither pull requests are commented nor the commit is tagged, because the rollouts are not awaited
+	c.succeeded = c.deploys
+
+	if c.showNamespace {
+		fmt.Fprintln(w, "deployments successfully updated! check rollout status by `kubectl rollout status KIND/NAME --namespace NAMESPACE`")
 	} else {
-		ctx := context.Background()
-		ghClient := github.NewClient(ctx, refOpts.accessToken)
+		fmt.Fprintf(w, "deployments successfully updated! check rollout status by `kubectl rollout status KIND/NAME --namespace %s`\n", c.deploys[0].namespace)
+	}
+
+	return nil
+}
+
+// abort settles the namespaces patched before the deploy to other namespaces failed
+// They are rolled back if --auto-rollback is given, otherwise their rollouts are awaited if --wait is given.
+// Their GitHub Deployments are marked as failure, because the deploy failed as a whole
+func (c *clusterDeploy) abort(w io.Writer, failures namespaceFailures) {
+	deadline := time.Now().Add(deployOpts.timeout)
+
+	for _, d := range c.deploys {
+		_, failed := failures[d.namespace]
+
+		if failed && !deployOpts.autoRollback {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n")
+
+		if c.showNamespace {
+			printNamespaceHeader(w, d.namespace, "")
+		}
+
+		if deployOpts.autoRollback {
+			fmt.Fprintln(w, "deploy failed, rolling back all target workloads...")
+
+			reason := "deploy to other namespaces failed"
+			if failed {
+				reason = failures[d.namespace].Error()
+			}
+
+			if err := d.rollback(w, c.k8sClient, reason); err != nil {
+				fmt.Fprintf(w, "%s\n", err)
+
+				if !failed {
+					failures[d.namespace] = err
+				}
+			}
+
+			if !failed {
+				setDeployGroupsStatus(d.groups, github.DeploymentStateFailure, "rolled back because deploy to other namespaces failed")
+			}
+
+			continue
+		}
+
+		if deployOpts.wait {
+			if err := waitForRollouts(w, c.k8sClient, d.updatedWorkloads, time.Until(deadline)); err != nil {
+				failures[d.namespace] = err
+				setDeployGroupsStatus(d.groups, github.DeploymentStateFailure, err.Error())
+
+				continue
+			}
+		}
+
+		setDeployGroupsStatus(d.groups, github.DeploymentStateFailure, "deployments updated, but deploy to other namespaces failed")
+	}
+}
+
+// finish runs the post-deploy steps for the namespaces deployed successfully
+func (c *clusterDeploy) finish(ghClient *github.Client) error {
+	for _, d := range c.succeeded {
+		if err := d.finish(c.k8sClient, ghClient, c.cache); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// namespaceDeploy represents the deploy to the target workloads in one namespace
+type namespaceDeploy struct {
+	cause            string
+	containers       map[string][]*kubernetes.Container
+	cronJobs         []*kubernetes.CronJob
+	groups           []*deployGroup
+	images           map[string]map[string]string
+	namespace        string
+	updatedCronJobs  []*kubernetes.CronJob
+	updatedWorkloads []kubernetes.Workload
+	workloads        []kubernetes.Workload
+}
 
-		sha1, err := ghClient.CommitFronRef(repo, deployOpts.ref)
+// deployCache holds the results shared by namespaces to avoid duplicate checks
+// Tags and release notes are published once per repository and tag, even if namespaces share them
+type deployCache struct {
+	checkedImages   map[string]bool
+	checkedStatuses map[string]bool
+	commits         map[string]string
+	pinnedImages    map[string]string
+	releases        map[string]bool
+	startedAt       time.Time
+}
+
+func newDeployCache() *deployCache {
+	return &deployCache{
+		checkedImages:   map[string]bool{},
+		checkedStatuses: map[string]bool{},
+		commits:         map[string]string{},
+		pinnedImages:    map[string]string{},
+		releases:        map[string]bool{},
+		startedAt:       time.Now(),
+	}
+}
+
+// released returns whether the release of the group has been published, and marks it as published
+func (c *deployCache) released(g *deployGroup) bool {
+	key := g.repo + "@" + g.sha1 + "@" + g.releaseTag
+
+	if c.releases[key] {
+		return true
+	}
+
+	c.releases[key] = true
+
+	return false
+}
+
+// planNamespaceDeploy detects the target workloads in the namespace and composes their new images
+// Images, commit statuses and downgrade are checked here, so nothing is changed in the cluster
+func planNamespaceDeploy(k8sClient *kubernetes.Client, ghClient *github.Client, namespace string, cache *deployCache) (*namespaceDeploy, error) {
+	targetWorkloads, targetContainers, err := detectTargetWorkloads(k8sClient, namespace, deployOpts.selector)
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobs, err := k8sClient.ListCronJobs(namespace, deployOpts.selector)
+	if err != nil {
+		if err != kubernetes.ErrCronJobsNotServed {
+			return nil, errors.Wrap(err, "failed to retrieve CronJobs")
+		}
+
+		fmt.Printf("WARNING: CronJobs in namespace %q are not deployed because %s\n", namespace, err)
+	}
+
+	targetCronJobs := []*kubernetes.CronJob{}
+
+	for _, j := range cronJobs {
+		if j.IsDeployTarget() {
+			targetCronJobs = append(targetCronJobs, j)
+		}
+	}
+
+	for _, j := range targetCronJobs {
+		cs, err := j.DeployTargetContainers()
 		if err != nil {
-			return errors.Wrapf(err, "failed to retrieve commit SHA-1 matched to ref %q in repo %q", deployOpts.ref, repo)
+			return nil, errors.Wrapf(err, "failed to retrieve deploy target containers of CronJob %q", j.Name())
 		}
 
-		if os.Getenv("GITHUB_DEPLOYMENT_ENABLED") == "1" {
-			cc, err := k8sClient.CurrentContext()
+		targetContainers[kubernetes.WorkloadKey(j)] = cs
+	}
+
+	groups, err := groupDeployTargets(targetWorkloads, targetCronJobs, targetContainers)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err = selectDeployGroups(groups, deployOpts.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &namespaceDeploy{
+		groups:    groups,
+		images:    map[string]map[string]string{},
+		namespace: namespace,
+	}
+
+	d.workloads, d.cronJobs, d.containers = mergeDeployGroups(groups)
+
+	if deployOpts.image != "" {
+		if _, err := kubernetes.GetTargetImage(d.containers); err != nil {
+			return nil, errors.Wrap(err, "failed to retrieve target image, restrict targets with --repo")
+		}
+	}
+
+	if ghClient != nil {
+		for _, g := range groups {
+			if sha1, ok := cache.commits[g.repo]; ok {
+				g.sha1 = sha1
+				continue
+			}
+
+			g.sha1, err = ghClient.CommitFronRef(g.repo, deployOpts.ref)
 			if err != nil {
-				return errors.Wrap(err, "failed to retrieve current context")
+				return nil, errors.Wrapf(err, "failed to retrieve commit SHA-1 matched to ref %q in repo %q", deployOpts.ref, g.repo)
 			}
 
-			did, err := ghClient.CreateDeployment(repo, deployOpts.ref, cc)
+			cache.commits[g.repo] = g.sha1
+		}
+	}
+
+	newImages := map[string]map[string]string{}
+	images := []string{}
+
+	for _, g := range groups {
+		for key, cs := range g.containers {
+			if _, ok := newImages[key]; !ok {
+				newImages[key] = map[string]string{}
+			}
+
+			for _, c := range cs {
+				image, err := newContainerImage(c, g.sha1)
+				if err != nil {
+					return nil, err
+				}
+
+				newImages[key][c.Name()] = image
+
+				if !containsString(images, image) {
+					images = append(images, image)
+				}
+			}
+		}
+	}
+
+	sort.Strings(images)
+
+	for _, image := range images {
+		if cache.checkedImages[image] {
+			continue
+		}
+
+		if deployOpts.waitForImage > 0 {
+			if err := waitForImage(k8sClient, d.workloads, image, deployOpts.waitForImage); err != nil {
+				return nil, err
+			}
+		} else if !deployOpts.skipImageCheck {
+			if err := checkImageExists(k8sClient, d.workloads, image); err != nil {
+				return nil, err
+			}
+		}
+
+		cache.checkedImages[image] = true
+	}
+
+	for _, g := range groups {
+		if g.sha1 == "" {
+			continue
+		}
+
+		if err := checkDowngrade(ghClient, g.repo, runningCommits(g.containers), g.sha1, deployOpts.allowDowngrade); err != nil {
+			return nil, err
+		}
+
+		if cache.checkedStatuses[g.repo+"@"+g.sha1] {
+			continue
+		}
+
+		if deployOpts.ignoreStatus {
+			fmt.Printf("WARNING: commit status of %s in repo %q is not checked because --ignore-status is given\n", g.sha1, g.repo)
+		} else if err := checkCommitStatus(ghClient, g.repo, g.sha1, requiredContexts(k8sClient, g.workloads)); err != nil {
+			return nil, err
+		}
+
+		cache.checkedStatuses[g.repo+"@"+g.sha1] = true
+	}
+
+	pinned := map[string]bool{}
+
+	for _, w := range d.workloads {
+		pinned[kubernetes.WorkloadKey(w)] = deployOpts.pinDigest || w.PinDigest()
+	}
+
+	for _, j := range d.cronJobs {
+		pinned[kubernetes.WorkloadKey(j)] = deployOpts.pinDigest || j.PinDigest()
+	}
+
+	for key, is := range newImages {
+		d.images[key] = map[string]string{}
+
+		for name, image := range is {
+			if !pinned[key] {
+				d.images[key][name] = image
+				continue
+			}
+
+			if _, ok := cache.pinnedImages[image]; !ok {
+				cache.pinnedImages[image], err = resolveImageDigest(k8sClient, d.workloads, image)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			d.images[key][name] = cache.pinnedImages[image]
+		}
+	}
+
+	for _, g := range groups {
+		if g.sha1 == "" {
+			continue
+		}
+
+		if deployOpts.releaseNotes || deployOpts.createRelease || deployOpts.releaseNotesPayload {
+			g.notes, err = composeReleaseNotes(ghClient, g.repo, runningCommits(g.containers), g.sha1)
 			if err != nil {
-				return errors.Wrap(err, "failed to create GitHub Deployment")
+				return nil, err
 			}
+		}
 
-			fmt.Printf("Deployment ID: %d\n", did)
+		if tagReleaseEnabled(deployOpts.tagRelease) || deployOpts.createRelease {
+			g.releaseTag, err = releaseTagNameInCluster(k8sClient, g.workloads, namespace, g.sha1, cache.startedAt)
+			if err != nil {
+				return nil, err
+			}
 		}
+	}
+
+	causeFlags := []string{}
 
-		newImage = image + ":" + sha1
+	if deployOpts.allowDowngrade {
+		causeFlags = append(causeFlags, "--allow-downgrade")
 	}
 
-	if deployOpts.dryRun {
-		for _, d := range targetDeployments {
-			c := targetContainers[d.Name()]
-			fmt.Printf("[dry-run] deploy to (deployment: %q, container: %q)\n", d.Name(), c.Name())
-			fmt.Printf("[dry-run]   before: %s\n", c.Image())
-			fmt.Printf("[dry-run]   after:  %s\n", newImage)
+	if deployOpts.ignoreStatus {
+		causeFlags = append(causeFlags, "--ignore-status")
+	}
+
+	if deployOpts.repo != "" {
+		causeFlags = append(causeFlags, "--repo "+deployOpts.repo)
+	}
+
+	if deployOpts.selector != "" {
+		causeFlags = append(causeFlags, fmt.Sprintf("--selector %q", deployOpts.selector))
+	}
+
+	d.cause = composeDeployCause(deployOpts.ref, deployOpts.image, deployOpts.tag, namespace, causeFlags...)
+
+	return d, nil
+}
+
+// printDryRun prints what will be done by the deploy
+func (d *namespaceDeploy) printDryRun(ghClient *github.Client, cache *deployCache) error {
+	for _, g := range d.groups {
+		if len(d.groups) > 1 {
+			printDeployGroup(g, d.images, true, "[dry-run] ")
 		}
-	} else {
-		for _, d := range targetDeployments {
-			c := targetContainers[d.Name()]
-			fmt.Printf("deploy to (deployment: %q, container: %q)\n", d.Name(), c.Name())
-			fmt.Printf("  before: %s\n", c.Image())
-			fmt.Printf("  after:  %s\n", newImage)
+
+		if deployOpts.diff && g.sha1 != "" {
+			if err := printCommitDiff(ghClient, g.repo, runningCommits(g.containers), g.sha1, "[dry-run] "); err != nil {
+				return err
+			}
+		}
+
+		if g.sha1 != "" && !cache.released(g) {
+			if g.notes != "" {
+				fmt.Printf("[dry-run] release notes:\n%s\n", g.notes)
+			}
+
+			if tagReleaseEnabled(deployOpts.tagRelease) && (deployOpts.wait || deployOpts.autoRollback) {
+				if err := tagRelease(ghClient, g.repo, g.releaseTag, g.sha1, true); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(d.groups) == 1 {
+			printDeployGroup(g, d.images, false, "[dry-run] ")
+		}
+	}
+
+	return nil
+}
+
+// createGitHubDeployments creates GitHub Deployment for each repository
+func (d *namespaceDeploy) createGitHubDeployments(k8sClient *kubernetes.Client, ghClient *github.Client) error {
+	var err error
+
+	for _, g := range d.groups {
+		if ghClient != nil {
+			var payload map[string]interface{}
+
+			if deployOpts.releaseNotesPayload {
+				payload = map[string]interface{}{
+					releaseNotesPayloadKey: g.notes,
+				}
+			}
+
+			description := composeGitHubDeploymentDescription(deployOpts.ref, firstImage(g, d.images), deployOpts.user)
+
+			g.ghDeployment, err = createGitHubDeploymentInCluster(k8sClient, ghClient, g.workloads, g.repo, deployOpts.ref, description, deployOpts.logURL, payload)
+			if err != nil {
+				return err
+			}
+		} else if githubDeploymentEnabled() {
+			image := firstImage(g, d.images)
+
+			if sha1 := commitFromImage(image); sha1 == "" {
+				fmt.Printf("GitHub Deployment is not created because the tag of image %q is not commit SHA-1\n", image)
+			} else {
+				description := composeGitHubDeploymentDescription("", image, deployOpts.user)
+
+				g.ghDeployment, err = createGitHubDeploymentInCluster(k8sClient, github.NewClient(context.Background(), deployOpts.accessToken), g.workloads, g.repo, sha1, description, deployOpts.logURL, nil)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// setImages patches the new images to the target workloads and CronJobs
+// This is called concurrently for namespaces, so nothing is printed
+func (d *namespaceDeploy) setImages(k8sClient *kubernetes.Client) error {
+	d.updatedWorkloads = make([]kubernetes.Workload, 0, len(d.workloads))
+
+	for _, w := range d.workloads {
+		neww, err := k8sClient.SetImage(w, d.images[kubernetes.WorkloadKey(w)], deployOpts.user, d.cause)
+		if err != nil {
+			return errors.Wrap(err, "failed to set image")
 		}
 
-		for _, d := range targetDeployments {
-			c := targetContainers[d.Name()]
+		d.updatedWorkloads = append(d.updatedWorkloads, neww)
+	}
+
+	d.updatedCronJobs = make([]*kubernetes.CronJob, 0, len(d.cronJobs))
 
-			if _, err := k8sClient.SetImage(
-				d, c.Name(), newImage, deployOpts.user, composeDeployCause(deployOpts.ref, deployOpts.image, deployOpts.tag, deployOpts.namespace),
+	for _, j := range d.cronJobs {
+		if _, err := k8sClient.SetCronJobImage(j, d.images[kubernetes.WorkloadKey(j)], deployOpts.user, d.cause); err != nil {
+			return errors.Wrap(err, "failed to set image")
+		}
+
+		d.updatedCronJobs = append(d.updatedCronJobs, j)
+	}
+
+	return nil
+}
+
+// waitForRollouts waits for the rollouts of updated workloads, and rolls back them on failure if --auto-rollback is given
+func (d *namespaceDeploy) waitForRollouts(w io.Writer, k8sClient *kubernetes.Client, timeout time.Duration) error {
+	if err := waitForRollouts(w, k8sClient, d.updatedWorkloads, timeout); err != nil {
+		setDeployGroupsStatus(d.groups, github.DeploymentStateFailure, err.Error())
+
+		if failures, ok := err.(rolloutFailures); ok && deployOpts.autoRollback {
+			rerr := rollbackWorkloads(
+				w, k8sClient, d.updatedWorkloads, d.containers, failures, deployOpts.user, d.cause,
+			)
+
+			if err := rollbackCronJobs(
+				w, k8sClient, d.cronJobs, d.containers, deployOpts.user, d.cause,
 			); err != nil {
-				return errors.Wrap(err, "failed to set image")
+				return err
 			}
+
+			return rerr
 		}
 
-		fmt.Printf("\n")
-		fmt.Printf("deployments successfully updated! check rollout status by `kubectl rollout status deployment/DEPLOYMENT --namespace %s`\n", deployOpts.namespace)
+		return err
 	}
 
+	setDeployGroupsStatus(d.groups, github.DeploymentStateSuccess, "")
+
 	return nil
 }
 
-func composeDeployCause(ref, image, tag, namespace string) string {
-	if ref != "" {
-		return fmt.Sprintf(`k8ship deploy %s --namespace "%s"`, ref, namespace)
+// rollback restores the images before deploy to the updated workloads and CronJobs
+func (d *namespaceDeploy) rollback(w io.Writer, k8sClient *kubernetes.Client, reason string) error {
+	if err := revertWorkloads(
+		w, k8sClient, d.updatedWorkloads, d.containers, rolloutFailures{}, reason, deployOpts.user, d.cause,
+	); err != nil {
+		return err
+	}
+
+	return rollbackCronJobs(w, k8sClient, d.updatedCronJobs, d.containers, deployOpts.user, d.cause)
+}
+
+// finish comments on pull requests, tags the commit and publishes release notes after successful deploy
+// Tag and release notes shared with the namespaces finished earlier are skipped
+func (d *namespaceDeploy) finish(k8sClient *kubernetes.Client, ghClient *github.Client, cache *deployCache) error {
+	for _, g := range d.groups {
+		if g.sha1 == "" {
+			continue
+		}
+
+		if commentPullRequestsEnabled(deployOpts.commentPRs) && (deployOpts.wait || deployOpts.autoRollback) {
+			commentOnPullRequests(k8sClient, ghClient, g.workloads, g.repo, runningCommits(g.containers), g.sha1, d.namespace, deployOpts.user)
+		}
+
+		if cache.released(g) {
+			continue
+		}
+
+		if tagReleaseEnabled(deployOpts.tagRelease) && (deployOpts.wait || deployOpts.autoRollback) {
+			if err := tagRelease(ghClient, g.repo, g.releaseTag, g.sha1, false); err != nil {
+				return err
+			}
+		}
+
+		if g.notes != "" {
+			if deployOpts.releaseNotes {
+				fmt.Printf("\n%s", g.notes)
+			}
+
+			if deployOpts.createRelease {
+				if err := createRelease(ghClient, g.repo, g.releaseTag, g.sha1, g.notes); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// newContainerImage returns the image to deploy to the given container
+// The tag of its current image is replaced with commit SHA-1 or --tag, unless --image is given
+func newContainerImage(container *kubernetes.Container, sha1 string) (string, error) {
+	if deployOpts.image != "" {
+		return deployOpts.image, nil
 	}
 
-	if image != "" {
-		return fmt.Sprintf(`k8ship deploy --image %s --namespace "%s"`, image, namespace)
+	img, err := kubernetes.ParseImage(container.Image())
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse image of container %q", container.Name())
 	}
 
-	if tag != "" {
-		return fmt.Sprintf(`k8ship deploy --tag %s --namespace "%s"`, tag, namespace)
+	if sha1 != "" {
+		return img.WithTag(sha1).String(), nil
+	}
+
+	return img.WithTag(deployOpts.tag).String(), nil
+}
+
+// firstImage returns the new image of the first container in the group
+func firstImage(g *deployGroup, images map[string]map[string]string) string {
+	for _, w := range g.workloads {
+		for _, c := range g.containers[kubernetes.WorkloadKey(w)] {
+			return images[kubernetes.WorkloadKey(w)][c.Name()]
+		}
 	}
 
 	return ""
 }
 
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detectTargetWorkloads returns the deploy target workloads matched to the label selector in the namespace
+// and their deploy target containers keyed by WorkloadKey
+func detectTargetWorkloads(k8sClient *kubernetes.Client, namespace, selector string) ([]kubernetes.Workload, map[string][]*kubernetes.Container, error) {
+	workloads, err := k8sClient.ListWorkloads(namespace, selector)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to retrieve workloads")
+	}
+
+	if len(workloads) == 0 {
+		return nil, nil, noWorkloadError(namespace, selector)
+	}
+
+	targetWorkloads := []kubernetes.Workload{}
+	targetContainers := map[string][]*kubernetes.Container{}
+
+	for _, w := range workloads {
+		if !w.IsDeployTarget() {
+			continue
+		}
+
+		cs, err := w.DeployTargetContainers()
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to retrieve deploy target containers of %s %q", w.Kind(), w.Name())
+		}
+
+		targetWorkloads = append(targetWorkloads, w)
+		targetContainers[kubernetes.WorkloadKey(w)] = cs
+	}
+
+	if len(targetWorkloads) == 0 {
+		return nil, nil, errors.New("no target workloads found")
+	}
+
+	return targetWorkloads, targetContainers, nil
+}
+
+// noWorkloadError returns the error that no workload is found in the namespace
+func noWorkloadError(namespace, selector string) error {
+	if selector == "" {
+		return errors.Errorf("no workload found in namespace %s", namespace)
+	}
+
+	return errors.Errorf("no workload matched to selector %q found in namespace %s", selector, namespace)
+}
+
+func composeDeployCause(ref, image, tag, namespace string, flags ...string) string {
+	var cause string
+
+	if ref != "" {
+		cause = fmt.Sprintf(`k8ship deploy %s --namespace "%s"`, ref, namespace)
+	} else if image != "" {
+		cause = fmt.Sprintf(`k8ship deploy --image %s --namespace "%s"`, image, namespace)
+	} else if tag != "" {
+		cause = fmt.Sprintf(`k8ship deploy --tag %s --namespace "%s"`, tag, namespace)
+	} else {
+		return ""
+	}
+
+	for _, f := range flags {
+		cause += " " + f
+	}
+
+	return cause
+}
+
 func init() {
 	RootCmd.AddCommand(deployCmd)
 
 	deployCmd.Flags().StringVar(&deployOpts.accessToken, "access-token", "", "GitHub access token")
+	deployCmd.Flags().BoolVar(&deployOpts.allNamespaces, "all-namespaces", false, "deploy to target workloads in all namespaces")
+	deployCmd.Flags().BoolVar(&deployOpts.allowDowngrade, "allow-downgrade", false, "deploy even if the commit is behind the running one")
+	deployCmd.Flags().BoolVar(&deployOpts.autoRollback, "auto-rollback", false, "roll back automatically if rollout fails (implies --wait)")
+	deployCmd.Flags().BoolVar(&deployOpts.commentPRs, "comment-pull-requests", false, "comment on pull requests merged since running revision after rollout (requires --wait)")
+	deployCmd.Flags().IntVar(&deployOpts.concurrency, "concurrency", defaultConcurrency, "maximum number of namespaces patched at the same time")
+	deployCmd.Flags().BoolVar(&deployOpts.createRelease, "create-release", false, "publish release notes as GitHub Release after deploy")
+	deployCmd.Flags().BoolVar(&deployOpts.diff, "diff", false, "show commits between running and target revision in dry run")
 	deployCmd.Flags().BoolVar(&deployOpts.dryRun, "dry-run", false, "dry run")
+	deployCmd.Flags().BoolVar(&deployOpts.ignoreStatus, "ignore-status", false, "deploy even if commit status or check runs are not successful")
 	deployCmd.Flags().StringVar(&deployOpts.image, "image", "", "image to deploy")
-	deployCmd.Flags().StringVarP(&deployOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace")
+	deployCmd.Flags().StringVar(&deployOpts.logURL, "log-url", "", "URL of deploy log attached to GitHub Deployment status")
+	deployCmd.Flags().StringVarP(&deployOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace (or comma-separated namespaces)")
+	deployCmd.Flags().BoolVar(&deployOpts.pinDigest, "pin-digest", false, "pin the image by digest of its manifest")
+	deployCmd.Flags().BoolVar(&deployOpts.releaseNotes, "release-notes", false, "print release notes generated from merged pull requests")
+	deployCmd.Flags().BoolVar(&deployOpts.releaseNotesPayload, "release-notes-payload", false, "attach release notes to the payload of GitHub Deployment")
+	deployCmd.Flags().StringVar(&deployOpts.repo, "repo", "", "deploy only workloads of the given GitHub repository (owner/name)")
+	deployCmd.Flags().StringVarP(&deployOpts.selector, "selector", "l", "", "label selector to filter target workloads (e.g. role=web)")
+	deployCmd.Flags().BoolVar(&deployOpts.sequential, "sequential", false, "deploy to clusters one by one, and stop at the first failure")
+	deployCmd.Flags().BoolVar(&deployOpts.skipImageCheck, "skip-image-check", false, "skip checking that the image exists in registry")
 	deployCmd.Flags().StringVar(&deployOpts.tag, "tag", "", "image tag to deploy")
+	deployCmd.Flags().BoolVar(&deployOpts.tagRelease, "tag-release", false, "create tag at the deployed commit on GitHub after rollout (requires --wait)")
+	deployCmd.Flags().DurationVar(&deployOpts.timeout, "timeout", defaultRolloutTimeout, "timeout of waiting for rollout")
 	deployCmd.Flags().StringVarP(&deployOpts.user, "user", "u", "", "image tag to deploy (default: current login user)")
+	deployCmd.Flags().BoolVar(&deployOpts.wait, "wait", false, "wait for rollout to finish")
+	deployCmd.Flags().DurationVar(&deployOpts.waitForImage, "wait-for-image", 0, "wait for the image to be pushed to registry up to the given duration")
+	deployCmd.Flags().Lookup("wait-for-image").NoOptDefVal = defaultWaitForImageTimeout.String()
 
 	if deployOpts.accessToken == "" {
 		deployOpts.accessToken = os.Getenv("GITHUB_ACCESS_TOKEN")
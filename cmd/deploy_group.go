@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+)
+
+// deployGroup represents the deploy target containers built from the same GitHub repository
+type deployGroup struct {
+	containers   map[string][]*kubernetes.Container
+	cronJobs     []*kubernetes.CronJob
+	ghDeployment *githubDeployment
+	notes        string
+	releaseTag   string
+	repo         string
+	sha1         string
+	workloads    []kubernetes.Workload
+}
+
+// groupDeployTargets groups the target containers of workloads and CronJobs by GitHub repository
+// containers must be keyed by WorkloadKey. CronJob containers join the group whose workload containers
+// use the same image, or the only group if there is one. Groups are sorted by repository
+func groupDeployTargets(workloads []kubernetes.Workload, cronJobs []*kubernetes.CronJob, containers map[string][]*kubernetes.Container) ([]*deployGroup, error) {
+	byRepo, err := kubernetes.GroupContainersByRepository(workloads, containers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve target repositories")
+	}
+
+	groups := make([]*deployGroup, 0, len(byRepo))
+	imageGroups := map[string][]*deployGroup{}
+
+	repos := make([]string, 0, len(byRepo))
+	for repo := range byRepo {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	for _, repo := range repos {
+		g := &deployGroup{
+			containers: byRepo[repo],
+			repo:       repo,
+		}
+
+		for _, w := range workloads {
+			cs, ok := g.containers[kubernetes.WorkloadKey(w)]
+			if !ok {
+				continue
+			}
+
+			g.workloads = append(g.workloads, w)
+
+			for _, c := range cs {
+				name := imageName(c.Image())
+				if !containsDeployGroup(imageGroups[name], g) {
+					imageGroups[name] = append(imageGroups[name], g)
+				}
+			}
+		}
+
+		groups = append(groups, g)
+	}
+
+	for _, j := range cronJobs {
+		for _, c := range containers[kubernetes.WorkloadKey(j)] {
+			var g *deployGroup
+
+			if gs := imageGroups[imageName(c.Image())]; len(gs) == 1 {
+				g = gs[0]
+			} else if len(gs) == 0 && len(groups) == 1 {
+				g = groups[0]
+			} else {
+				return nil, errors.Errorf("failed to determine GitHub repository for container %q in CronJob %q", c.Name(), j.Name())
+			}
+
+			if _, ok := g.containers[kubernetes.WorkloadKey(j)]; !ok {
+				g.cronJobs = append(g.cronJobs, j)
+			}
+
+			g.containers[kubernetes.WorkloadKey(j)] = append(g.containers[kubernetes.WorkloadKey(j)], c)
+		}
+	}
+
+	return groups, nil
+}
+
+// selectDeployGroups returns the group of the given repository
+// All groups are returned if repo is empty
+func selectDeployGroups(groups []*deployGroup, repo string) ([]*deployGroup, error) {
+	if repo == "" {
+		return groups, nil
+	}
+
+	for _, g := range groups {
+		if g.repo == repo {
+			return []*deployGroup{g}, nil
+		}
+	}
+
+	repos := make([]string, 0, len(groups))
+	for _, g := range groups {
+		repos = append(repos, g.repo)
+	}
+
+	return nil, errors.Errorf("no target workload found for repository %q (found: %s)", repo, strings.Join(repos, ", "))
+}
+
+// mergeDeployGroups returns the workloads, CronJobs and containers of all the given groups
+// A workload whose containers belong to several groups appears only once
+func mergeDeployGroups(groups []*deployGroup) ([]kubernetes.Workload, []*kubernetes.CronJob, map[string][]*kubernetes.Container) {
+	workloads := []kubernetes.Workload{}
+	cronJobs := []*kubernetes.CronJob{}
+	containers := map[string][]*kubernetes.Container{}
+
+	for _, g := range groups {
+		for _, w := range g.workloads {
+			if _, ok := containers[kubernetes.WorkloadKey(w)]; !ok {
+				workloads = append(workloads, w)
+			}
+
+			containers[kubernetes.WorkloadKey(w)] = append(containers[kubernetes.WorkloadKey(w)], g.containers[kubernetes.WorkloadKey(w)]...)
+		}
+
+		for _, j := range g.cronJobs {
+			if _, ok := containers[kubernetes.WorkloadKey(j)]; !ok {
+				cronJobs = append(cronJobs, j)
+			}
+
+			containers[kubernetes.WorkloadKey(j)] = append(containers[kubernetes.WorkloadKey(j)], g.containers[kubernetes.WorkloadKey(j)]...)
+		}
+	}
+
+	return workloads, cronJobs, containers
+}
+
+// printDeployGroup prints the images of containers in the group before and after deploy
+// images must be keyed by WorkloadKey and then container name
+func printDeployGroup(g *deployGroup, images map[string]map[string]string, header bool, prefix string) {
+	if header {
+		if g.sha1 == "" {
+			fmt.Printf("%srepository: %s\n", prefix, g.repo)
+		} else {
+			fmt.Printf("%srepository: %s (commit: %s)\n", prefix, g.repo, g.sha1)
+		}
+	}
+
+	for _, w := range g.workloads {
+		for _, c := range g.containers[kubernetes.WorkloadKey(w)] {
+			fmt.Printf("%sdeploy to (%s: %q, container: %q)\n", prefix, strings.ToLower(w.Kind()), w.Name(), c.Name())
+			fmt.Printf("%s  before: %s\n", prefix, c.Image())
+			fmt.Printf("%s  after:  %s\n", prefix, images[kubernetes.WorkloadKey(w)][c.Name()])
+		}
+	}
+
+	for _, j := range g.cronJobs {
+		for _, c := range g.containers[kubernetes.WorkloadKey(j)] {
+			fmt.Printf("%sdeploy to (cronjob: %q, container: %q)\n", prefix, j.Name(), c.Name())
+			fmt.Printf("%s  before: %s\n", prefix, c.Image())
+			fmt.Printf("%s  after:  %s\n", prefix, images[kubernetes.WorkloadKey(j)][c.Name()])
+		}
+	}
+}
+
+// setDeployGroupsStatus sets the status of GitHub Deployments created for the groups
+func setDeployGroupsStatus(groups []*deployGroup, state, description string) {
+	for _, g := range groups {
+		g.ghDeployment.setStatus(state, description)
+	}
+}
+
+func containsDeployGroup(groups []*deployGroup, group *deployGroup) bool {
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+// imageName returns the image name without tag and digest
+// The given string is returned as it is if it cannot be parsed
+func imageName(image string) string {
+	img, err := kubernetes.ParseImage(image)
+	if err != nil {
+		return image
+	}
+
+	return img.Name()
+}
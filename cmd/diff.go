@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/dtan4/k8ship/github"
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff BRANCH|COMMIT_SHA1",
+	Short: "Show commits between running and target revision",
+	RunE:  doDiff,
+}
+
+var diffOpts = struct {
+	accessToken string
+	container   string
+	deployment  string
+	namespace   string
+	repo        string
+}{}
+
+func doDiff(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("ref (branch, full commit SHA-1 or short commit SHA-1) must be given")
+	}
+	ref := args[0]
+
+	kubeContext, err := singleKubeContext()
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, kubeContext)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Kubernetes client")
+	}
+
+	_, targetContainers, repo, err := detectTargets(k8sClient, diffOpts.namespace, diffOpts.deployment, diffOpts.container, diffOpts.repo)
+	if err != nil {
+		return err
+	}
+
+	ghClient := github.NewClient(context.Background(), diffOpts.accessToken)
+
+	sha1, err := ghClient.CommitFronRef(repo, ref)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve commit SHA-1 matched to ref %q in repo %q", ref, repo)
+	}
+
+	return printCommitDiff(ghClient, repo, runningCommits(targetContainers), sha1, "")
+}
+
+// detectTargets returns the target workloads, their target containers keyed by WorkloadKey and GitHub repository
+// If Deployment or container is given, they are detected in the same way as `ref`,
+// otherwise deploy target workloads of the given repository are detected in the same way as `deploy`
+func detectTargets(k8sClient *kubernetes.Client, namespace, deploymentName, containerName, repo string) ([]kubernetes.Workload, map[string][]*kubernetes.Container, string, error) {
+	if deploymentName == "" && containerName == "" {
+		workloads, containers, err := detectTargetWorkloads(k8sClient, namespace, "")
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		groups, err := groupDeployTargets(workloads, []*kubernetes.CronJob{}, containers)
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		groups, err = selectDeployGroups(groups, repo)
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		if len(groups) > 1 {
+			repos := make([]string, 0, len(groups))
+			for _, g := range groups {
+				repos = append(repos, g.repo)
+			}
+
+			return nil, nil, "", errors.Errorf("multiple repositories found (%s), specify one with --repo", strings.Join(repos, ", "))
+		}
+
+		return groups[0].workloads, groups[0].containers, groups[0].repo, nil
+	}
+
+	deployment, err := k8sClient.DetectTargetDeployment(namespace, deploymentName)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "failed to detect target Deployment")
+	}
+
+	container, err := k8sClient.DetectTargetContainer(deployment, containerName)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "failed to detect target container")
+	}
+
+	r, err := deployment.Repository(container.Name())
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "failed to retrieve target repository")
+	}
+
+	if repo != "" && r != repo {
+		return nil, nil, "", errors.Errorf("container %q in Deployment %q is built from repository %q, not %q", container.Name(), deployment.Name(), r, repo)
+	}
+
+	return []kubernetes.Workload{deployment}, map[string][]*kubernetes.Container{kubernetes.WorkloadKey(deployment): {container}}, r, nil
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffOpts.accessToken, "access-token", "", "GitHub access token")
+	diffCmd.Flags().StringVarP(&diffOpts.container, "container", "c", "", "target container")
+	diffCmd.Flags().StringVarP(&diffOpts.deployment, "deployment", "d", "", "target Deployment")
+	diffCmd.Flags().StringVarP(&diffOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace")
+	diffCmd.Flags().StringVar(&diffOpts.repo, "repo", "", "target GitHub repository (owner/name)")
+
+	if diffOpts.accessToken == "" {
+		diffOpts.accessToken = os.Getenv("GITHUB_ACCESS_TOKEN")
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dtan4/k8ship/github"
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+)
+
+// commentPullRequestsEnabled returns whether to comment on pull requests after deploy
+// by the flag or `github.comment_pull_requests` in config file
+func commentPullRequestsEnabled(flag bool) bool {
+	if flag {
+		return true
+	}
+
+	b := configBool("github.comment_pull_requests")
+
+	return b != nil && *b
+}
+
+// checkCommentPullRequests checks that the rollout is awaited if pull requests are commented
+// Comments say the pull requests are live, so they are posted only after successful rollout
+func checkCommentPullRequests(flag, wait bool) error {
+	if wait || !commentPullRequestsEnabled(flag) {
+		return nil
+	}
+
+	if flag {
+		return errors.New("--comment-pull-requests requires --wait, because pull requests are commented after successful rollout")
+	}
+
+	fmt.Println("WARNING: pull requests are not commented because rollout is not awaited without --wait")
+
+	return nil
+}
+
+// commentOnPullRequests posts a comment on each pull request merged between the running commits and the deployed commit
+// The comment for the same environment and namespace is updated instead of posting new one
+// Failure is only warned because the deploy has already finished
+func commentOnPullRequests(k8sClient *kubernetes.Client, ghClient *github.Client, workloads []kubernetes.Workload, repo string, running []string, sha1, namespace, user string) {
+	numbers, err := mergedPullRequests(ghClient, repo, running, sha1)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: failed to retrieve merged pull requests: %s\n", err)
+		return
+	}
+
+	if len(numbers) == 0 {
+		return
+	}
+
+	opts, err := githubDeploymentOptions(k8sClient, workloads)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: failed to retrieve environment: %s\n", err)
+		return
+	}
+
+	cluster, err := k8sClient.CurrentContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: failed to retrieve current context: %s\n", err)
+		return
+	}
+
+	marker := fmt.Sprintf("<!-- k8ship:%s:%s -->", opts.Environment, namespace)
+	body := fmt.Sprintf(
+		"%s\n:rocket: Deployed to **%s** (cluster: `%s`, namespace: `%s`) by %s at %s\n\nCommit: %s\n",
+		marker, opts.Environment, cluster, namespace, user, time.Now().UTC().Format(time.RFC3339), sha1,
+	)
+
+	for _, n := range numbers {
+		if err := ghClient.CreateOrUpdateComment(repo, n, marker, body); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: failed to comment on pull request #%d: %s\n", n, err)
+			continue
+		}
+
+		fmt.Printf("commented on pull request #%d\n", n)
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dtan4/k8ship/github"
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+)
+
+// runningCommits returns the commit SHA-1s embedded in the image tags of the given containers
+// Images whose tag is not full commit SHA-1 are ignored
+func runningCommits(containers map[string][]*kubernetes.Container) []string {
+	set := map[string]bool{}
+
+	for _, cs := range containers {
+		for _, c := range cs {
+			if sha1 := commitFromImage(c.Image()); sha1 != "" {
+				set[sha1] = true
+			}
+		}
+	}
+
+	commits := make([]string, 0, len(set))
+
+	for sha1 := range set {
+		commits = append(commits, sha1)
+	}
+
+	sort.Strings(commits)
+
+	return commits
+}
+
+// checkDowngrade compares the target commit with the running commits via GitHub compare API
+// Error is returned if the target commit is behind the running one unless allowDowngrade is true,
+// and warning is printed if they are diverged
+func checkDowngrade(client *github.Client, repo string, running []string, target string, allowDowngrade bool) error {
+	for _, base := range running {
+		if base == target {
+			continue
+		}
+
+		cmp, err := client.CompareCommits(repo, base, target)
+		if err != nil {
+			if allowDowngrade {
+				fmt.Fprintf(os.Stderr, "WARNING: failed to compare running commit %s with %s: %s\n", base, target, err)
+				continue
+			}
+
+			return errors.Wrapf(err, "failed to compare running commit %s with %s in repo %q (use --allow-downgrade to deploy anyway)", base, target, repo)
+		}
+
+		switch cmp.Status {
+		case github.ComparisonStatusBehind:
+			if !allowDowngrade {
+				return errors.Errorf("commit %s is %d commits behind running commit %s in repo %q (use --allow-downgrade to deploy anyway)", target, cmp.BehindBy, base, repo)
+			}
+
+			fmt.Fprintf(os.Stderr, "WARNING: commit %s is %d commits behind running commit %s\n", target, cmp.BehindBy, base)
+		case github.ComparisonStatusDiverged:
+			fmt.Fprintf(os.Stderr, "WARNING: commit %s is diverged from running commit %s (%d ahead, %d behind)\n", target, base, cmp.AheadBy, cmp.BehindBy)
+		}
+	}
+
+	return nil
+}
+
+// printCommitDiff prints the commits between the running commits and the target commit,
+// and the pull requests merged by them
+func printCommitDiff(client *github.Client, repo string, running []string, target string, prefix string) error {
+	if len(running) == 0 {
+		fmt.Printf("%sno running commit found, the image tag is not commit SHA-1\n", prefix)
+		return nil
+	}
+
+	for _, base := range running {
+		fmt.Printf("%s%s: %s...%s\n", prefix, repo, base, target)
+
+		if base == target {
+			fmt.Printf("%s  (identical)\n", prefix)
+			continue
+		}
+
+		cmp, err := client.CompareCommits(repo, base, target)
+		if err != nil {
+			return errors.Wrapf(err, "failed to compare running commit %s with %s in repo %q", base, target, repo)
+		}
+
+		if cmp.Status == github.ComparisonStatusBehind || cmp.Status == github.ComparisonStatusDiverged {
+			fmt.Printf("%s  (%s: %d ahead, %d behind)\n", prefix, cmp.Status, cmp.AheadBy, cmp.BehindBy)
+		}
+
+		prs := []string{}
+		seen := map[int]bool{}
+
+		for _, c := range cmp.Commits {
+			fmt.Printf("%s  %s %s %s\n", prefix, c.ShortSHA(), c.Author, c.Subject())
+
+			n, err := pullRequestNumber(client, repo, c)
+			if err != nil {
+				return err
+			}
+
+			if n > 0 && !seen[n] {
+				seen[n] = true
+				prs = append(prs, fmt.Sprintf("#%d", n))
+			}
+		}
+
+		if len(cmp.Commits) < cmp.AheadBy {
+			fmt.Printf("%s  ... and %d more commits\n", prefix, cmp.AheadBy-len(cmp.Commits))
+		}
+
+		if len(prs) > 0 {
+			fmt.Printf("%s  merged pull requests: %s\n", prefix, strings.Join(prs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// mergedPullRequests returns the numbers of pull requests merged between the running commits and the target commit
+func mergedPullRequests(client *github.Client, repo string, running []string, target string) ([]int, error) {
+	numbers := []int{}
+	seen := map[int]bool{}
+
+	for _, base := range running {
+		if base == target {
+			continue
+		}
+
+		cmp, err := client.CompareCommits(repo, base, target)
+		if err != nil {
+			return []int{}, errors.Wrapf(err, "failed to compare running commit %s with %s in repo %q", base, target, repo)
+		}
+
+		if len(cmp.Commits) < cmp.AheadBy {
+			fmt.Fprintf(os.Stderr, "WARNING: only %d of %d commits between %s and %s are returned, pull requests merged by the rest are missed\n", len(cmp.Commits), cmp.AheadBy, base, target)
+		}
+
+		for _, c := range cmp.Commits {
+			n, err := pullRequestNumber(client, repo, c)
+			if err != nil {
+				return []int{}, err
+			}
+
+			if n > 0 && !seen[n] {
+				seen[n] = true
+				numbers = append(numbers, n)
+			}
+		}
+	}
+
+	return numbers, nil
+}
+
+// pullRequestNumber returns the number of pull request which merged the given commit
+// The commit message is checked first, and then GitHub is asked for the pull request of rebase-merged commit
+// 0 is returned if the commit was not merged by pull request
+func pullRequestNumber(client *github.Client, repo string, c *github.Commit) (int, error) {
+	if n := c.PullRequestNumber(); n > 0 {
+		return n, nil
+	}
+
+	n, err := client.CommitPullRequestNumber(repo, c.SHA)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to find pull request of commit %s in repo %q", c.ShortSHA(), repo)
+	}
+
+	return n, nil
+}
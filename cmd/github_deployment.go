@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/dtan4/k8ship/github"
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+)
+
+var (
+	commitSHA1Regexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// githubDeployment represents GitHub Deployment which tracks the deploy
+// All methods of nil githubDeployment do nothing,
+// so that callers don't have to check whether GitHub Deployment is enabled
+type githubDeployment struct {
+	client        *github.Client
+	id            int64
+	repo          string
+	statusOptions github.DeploymentStatusOptions
+}
+
+// githubDeploymentEnabled returns whether GitHub Deployment is enabled
+// by GITHUB_DEPLOYMENT_ENABLED or `github.deployment_enabled` in config file
+func githubDeploymentEnabled() bool {
+	if v := os.Getenv("GITHUB_DEPLOYMENT_ENABLED"); v != "" {
+		return v == "1"
+	}
+
+	if b := configBool("github.deployment_enabled"); b != nil {
+		return *b
+	}
+
+	return false
+}
+
+// createGitHubDeployment creates GitHub Deployment in "pending" state
+func createGitHubDeployment(client *github.Client, repo, ref, logURL string, opts *github.DeploymentOptions) (*githubDeployment, error) {
+	id, err := client.CreateDeployment(repo, ref, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create GitHub Deployment")
+	}
+
+	fmt.Printf("GitHub Deployment ID: %d (environment: %q)\n", id, opts.Environment)
+
+	return &githubDeployment{
+		client: client,
+		id:     id,
+		repo:   repo,
+		statusOptions: github.DeploymentStatusOptions{
+			AutoInactive:   opts.AutoInactive,
+			EnvironmentURL: opts.EnvironmentURL,
+			LogURL:         logURL,
+		},
+	}, nil
+}
+
+// createGitHubDeploymentInCluster creates GitHub Deployment for the given Kubernetes workloads
+// payload is attached to GitHub Deployment if not nil
+// nil is returned if GitHub Deployment is not enabled
+func createGitHubDeploymentInCluster(k8sClient *kubernetes.Client, ghClient *github.Client, workloads []kubernetes.Workload, repo, ref, description, logURL string, payload map[string]interface{}) (*githubDeployment, error) {
+	if !githubDeploymentEnabled() {
+		return nil, nil
+	}
+
+	opts, err := githubDeploymentOptions(k8sClient, workloads)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Description = description
+	opts.Payload = payload
+
+	return createGitHubDeployment(ghClient, repo, ref, logURL, opts)
+}
+
+// createGitHubDeploymentForImage creates GitHub Deployment for the image deployed to the container of the workload
+// The image tag is regarded as the deployed commit, so nil is returned if it is not full commit SHA-1
+func createGitHubDeploymentForImage(k8sClient *kubernetes.Client, accessToken string, workload kubernetes.Workload, container, image, user, logURL string) (*githubDeployment, error) {
+	if !githubDeploymentEnabled() {
+		return nil, nil
+	}
+
+	sha1 := commitFromImage(image)
+	if sha1 == "" {
+		fmt.Printf("GitHub Deployment is not created because the tag of image %q is not commit SHA-1\n", image)
+		return nil, nil
+	}
+
+	repo, err := workload.Repository(container)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve target repository")
+	}
+
+	return createGitHubDeploymentInCluster(
+		k8sClient, github.NewClient(context.Background(), accessToken), []kubernetes.Workload{workload}, repo, sha1, composeGitHubDeploymentDescription("", image, user), logURL, nil,
+	)
+}
+
+// setImageWithGitHubDeployment sets the image to the container of the workload, and waits for its rollout if wait is true
+// The state of GitHub Deployment follows the result.
+// The updated workload is returned even if the rollout fails, so that the caller can roll it back
+func setImageWithGitHubDeployment(k8sClient *kubernetes.Client, ghDeployment *githubDeployment, workload kubernetes.Workload, container, image, user, cause string, wait bool, timeout time.Duration) (kubernetes.Workload, error) {
+	newWorkload, err := k8sClient.SetImage(workload, map[string]string{container: image}, user, cause)
+	if err != nil {
+		ghDeployment.setStatus(github.DeploymentStateError, err.Error())
+		return nil, errors.Wrap(err, "failed to set image")
+	}
+
+	ghDeployment.setStatus(github.DeploymentStateInProgress, "")
+
+	fmt.Printf("\n")
+
+	if !wait {
+		return newWorkload, nil
+	}
+
+	if err := waitForRollouts(os.Stdout, k8sClient, []kubernetes.Workload{newWorkload}, timeout); err != nil {
+		ghDeployment.setStatus(github.DeploymentStateFailure, err.Error())
+		return newWorkload, err
+	}
+
+	ghDeployment.setStatus(github.DeploymentStateSuccess, "")
+
+	return newWorkload, nil
+}
+
+// githubDeploymentOptions composes the options of GitHub Deployment from the annotations of Kubernetes workloads
+// The first value found in workloads is used, and config file fills the rest
+// Environment name defaults to the current context name
+func githubDeploymentOptions(k8sClient *kubernetes.Client, workloads []kubernetes.Workload) (*github.DeploymentOptions, error) {
+	opts := &github.DeploymentOptions{}
+
+	for _, w := range workloads {
+		a := k8sClient.GitHubAnnotations(w)
+
+		if opts.Environment == "" {
+			opts.Environment = a.Environment
+		}
+
+		if opts.AutoInactive == nil {
+			opts.AutoInactive = a.AutoInactive
+		}
+
+		if opts.ProductionEnvironment == nil {
+			opts.ProductionEnvironment = a.ProductionEnvironment
+		}
+
+		if opts.TransientEnvironment == nil {
+			opts.TransientEnvironment = a.TransientEnvironment
+		}
+
+		if opts.EnvironmentURL == "" {
+			u, err := k8sClient.EnvironmentURL(w)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to retrieve environment URL of %s %q", w.Kind(), w.Name())
+			}
+
+			opts.EnvironmentURL = u
+		}
+	}
+
+	if opts.Environment == "" {
+		opts.Environment = configString("github.environment")
+	}
+
+	if opts.AutoInactive == nil {
+		opts.AutoInactive = configBool("github.auto_inactive")
+	}
+
+	if opts.ProductionEnvironment == nil {
+		opts.ProductionEnvironment = configBool("github.production_environment")
+	}
+
+	if opts.TransientEnvironment == nil {
+		opts.TransientEnvironment = configBool("github.transient_environment")
+	}
+
+	if opts.Environment == "" {
+		cc, err := k8sClient.CurrentContext()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to retrieve current context")
+		}
+
+		opts.Environment = cc
+	}
+
+	return opts, nil
+}
+
+// composeGitHubDeploymentDescription composes the description of GitHub Deployment
+// e.g. "k8ship deploy master (quay.io/dtan4/app:0123abc) by dtan4"
+func composeGitHubDeploymentDescription(ref, image, user string) string {
+	description := "k8ship deploy"
+
+	if ref != "" {
+		description += " " + ref
+	}
+
+	if image != "" {
+		description += fmt.Sprintf(" (%s)", image)
+	}
+
+	if user != "" {
+		description += " by " + user
+	}
+
+	return description
+}
+
+// commitFromImage returns the commit SHA-1 if the tag of the given image is full commit SHA-1,
+// otherwise empty string
+func commitFromImage(image string) string {
+	img, err := kubernetes.ParseImage(image)
+	if err != nil {
+		return ""
+	}
+
+	if !commitSHA1Regexp.MatchString(img.Tag) {
+		return ""
+	}
+
+	return img.Tag
+}
+
+// setStatus updates the state of GitHub Deployment
+// failure of update is only warned because it must not stop the deploy
+func (d *githubDeployment) setStatus(state, description string) {
+	if d == nil {
+		return
+	}
+
+	opts := d.statusOptions
+	opts.Description = description
+
+	if err := d.client.UpdateDeploymentStatus(d.repo, d.id, state, &opts); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: failed to update GitHub Deployment %d: %s\n", d.id, err)
+	}
+}
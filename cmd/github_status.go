@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dtan4/k8ship/github"
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+)
+
+// requiredContexts returns the commit status contexts which must be successful to deploy
+// Contexts listed in `github-required-contexts` annotation of workloads are merged,
+// and `github.required_contexts` in config file is used if no workload has the annotation
+// Empty list means that all statuses and check runs must be successful
+func requiredContexts(k8sClient *kubernetes.Client, workloads []kubernetes.Workload) []string {
+	contexts := []string{}
+	set := map[string]bool{}
+	annotated := false
+
+	for _, w := range workloads {
+		cs := k8sClient.GitHubAnnotations(w).RequiredContexts
+		if cs == nil {
+			continue
+		}
+
+		annotated = true
+
+		for _, c := range cs {
+			if !set[c] {
+				set[c] = true
+				contexts = append(contexts, c)
+			}
+		}
+	}
+
+	if !annotated {
+		return configStringSlice("github.required_contexts")
+	}
+
+	return contexts
+}
+
+// checkCommitStatus returns error unless the statuses and check runs of the given commit are green
+// If required contexts are given, only these contexts are checked and all of them must be reported.
+// Commit without any status or check run, or with pending one in any context, is not green either
+func checkCommitStatus(client *github.Client, repo, sha1 string, required []string) error {
+	statuses, err := client.CommitStatuses(repo, sha1)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve commit status of %s in repo %q", sha1, repo)
+	}
+
+	if len(statuses) == 0 {
+		return errors.Errorf("commit %s in repo %q is not green: no status or check run is reported (use --ignore-status to deploy anyway)", sha1, repo)
+	}
+
+	states := map[string]string{}
+	contexts := []string{}
+
+	for _, s := range statuses {
+		// check runs are listed with `filter=latest`, so the first one is used for the same context
+		if _, ok := states[s.Context]; ok {
+			continue
+		}
+
+		states[s.Context] = s.State
+		contexts = append(contexts, s.Context)
+	}
+
+	checked := contexts
+	if len(required) > 0 {
+		checked = required
+	}
+
+	problems := []string{}
+
+	for _, c := range checked {
+		state, ok := states[c]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q is not reported", c))
+			continue
+		}
+
+		if state != github.CommitStateSuccess {
+			problems = append(problems, fmt.Sprintf("%q is %s", c, state))
+		}
+	}
+
+	// combined state is pending while any status or check run is running, even if it is not required
+	for _, c := range contexts {
+		if states[c] == github.CommitStatePending && !containsString(checked, c) {
+			problems = append(problems, fmt.Sprintf("%q is %s", c, github.CommitStatePending))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("commit %s in repo %q is not green: %s (use --ignore-status to deploy anyway)", sha1, repo, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/dtan4/k8ship/github"
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTagTemplate = `deploy-{{.Environment}}-{{.Time.Format "2006-01-02T15-04"}}`
+)
+
+// tagNameParams represents the values available in tag name template
+type tagNameParams struct {
+	Environment string
+	Namespace   string
+	SHA         string
+	ShortSHA    string
+	Time        time.Time
+}
+
+// tagReleaseEnabled returns whether to tag the deployed commit
+// by the flag or `github.tag_release` in config file
+func tagReleaseEnabled(flag bool) bool {
+	if flag {
+		return true
+	}
+
+	b := configBool("github.tag_release")
+
+	return b != nil && *b
+}
+
+// checkTagRelease checks that the rollout is awaited if the deployed commit is tagged
+// Tag records the release running in the environment, so it is created only after successful rollout
+func checkTagRelease(flag, wait bool) error {
+	if wait || !tagReleaseEnabled(flag) {
+		return nil
+	}
+
+	if flag {
+		return errors.New("--tag-release requires --wait, because the commit is tagged after successful rollout")
+	}
+
+	fmt.Println("WARNING: the deployed commit is not tagged because rollout is not awaited without --wait")
+
+	return nil
+}
+
+// releaseTagName renders the tag name from `github.tag_template` in config file
+// (default: deploy-ENVIRONMENT-2006-01-02T15-04)
+// Time is converted to UTC
+func releaseTagName(environment, namespace, sha1 string, t time.Time) (string, error) {
+	text := configString("github.tag_template")
+	if text == "" {
+		text = defaultTagTemplate
+	}
+
+	tmpl, err := template.New("tag").Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid tag template %q", text)
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, tagNameParams{
+		Environment: environment,
+		Namespace:   namespace,
+		SHA:         sha1,
+		ShortSHA:    shortSHA(sha1),
+		Time:        t.UTC(),
+	}); err != nil {
+		return "", errors.Wrapf(err, "failed to render tag template %q", text)
+	}
+
+	return buf.String(), nil
+}
+
+// releaseTagNameInCluster renders the tag name with the GitHub Deployment environment of the given workloads
+func releaseTagNameInCluster(k8sClient *kubernetes.Client, workloads []kubernetes.Workload, namespace, sha1 string, t time.Time) (string, error) {
+	opts, err := githubDeploymentOptions(k8sClient, workloads)
+	if err != nil {
+		return "", err
+	}
+
+	return releaseTagName(opts.Environment, namespace, sha1, t)
+}
+
+// tagRelease creates tag at the deployed commit unless the tag exists
+// Annotated tag is created if `github.tag_annotated` is true in config file
+func tagRelease(client *github.Client, repo, tag, sha1 string, dryRun bool) error {
+	exists, err := client.TagExists(repo, tag)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check tag %q in repo %q", tag, repo)
+	}
+
+	prefix := ""
+	if dryRun {
+		prefix = "[dry-run] "
+	}
+
+	if exists {
+		fmt.Printf("%stag %q already exists in repo %q, skipped\n", prefix, tag, repo)
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("%stag %q will be created at %s in repo %q\n", prefix, tag, sha1, repo)
+		return nil
+	}
+
+	var message string
+
+	if b := configBool("github.tag_annotated"); b != nil && *b {
+		message = fmt.Sprintf("k8ship deploy %s", tag)
+	}
+
+	if err := client.CreateTag(repo, tag, sha1, message); err != nil {
+		return errors.Wrapf(err, "failed to create tag %q in repo %q", tag, repo)
+	}
+
+	fmt.Printf("tag %q created at %s in repo %q\n", tag, sha1, repo)
+
+	return nil
+}
@@ -2,7 +2,7 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -24,70 +24,114 @@ var historyCmd = &cobra.Command{
 }
 
 var historyOpts = struct {
-	all       bool
-	namespace string
+	all           bool
+	allNamespaces bool
+	namespace     string
+	selector      string
+	sequential    bool
 }{}
 
 func doHistory(cmd *cobra.Command, args []string) error {
-	client, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, rootOpts.context)
+	contexts := kubeContexts()
+
+	f := func(i int, w io.Writer) error {
+		return printClusterHistory(contexts[i], w)
+	}
+
+	var errs []error
+
+	if historyOpts.sequential || len(contexts) == 1 {
+		errs = runClustersSequentially(contexts, f)
+	} else {
+		errs = runClustersInParallel(contexts, f)
+	}
+
+	return clusterResults(contexts, errs)
+}
+
+// printClusterHistory writes the revisions of target workloads in the cluster of the given context
+func printClusterHistory(kubeContext string, out io.Writer) error {
+	client, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, kubeContext)
 	if err != nil {
 		return errors.Wrap(err, "failed to create Kubernetes client")
 	}
 
-	ds, err := client.ListDeployments(historyOpts.namespace)
+	namespaces, err := targetNamespaces(client, historyOpts.namespace, historyOpts.allNamespaces, historyOpts.selector, true)
 	if err != nil {
-		return errors.Wrap(err, "failed to retrieve Deployments")
+		return err
 	}
 
-	if len(ds) == 0 {
-		return errors.Errorf("no Deployment found in namespace %s", historyOpts.namespace)
+	showNamespace := historyOpts.allNamespaces || len(namespaces) > 1
+
+	for _, ns := range namespaces {
+		if showNamespace {
+			printNamespaceHeader(out, ns, "")
+			fmt.Fprintf(out, "\n")
+		}
+
+		if err := printHistory(client, ns, out); err != nil {
+			if showNamespace {
+				return errors.Wrapf(err, "failed to print history in namespace %s", ns)
+			}
+
+			return err
+		}
 	}
 
-	tds := []*kubernetes.Deployment{}
+	return nil
+}
+
+// printHistory writes the revisions of target workloads in the namespace
+func printHistory(client *kubernetes.Client, namespace string, out io.Writer) error {
+	ws, err := client.ListWorkloads(namespace, historyOpts.selector)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve workloads")
+	}
 
-	for _, d := range ds {
-		if d.IsDeployTarget() {
-			tds = append(tds, d)
+	if len(ws) == 0 {
+		return noWorkloadError(namespace, historyOpts.selector)
+	}
+
+	tws := []kubernetes.Workload{}
+
+	for _, w := range ws {
+		if w.IsDeployTarget() {
+			tws = append(tws, w)
 		}
 	}
 
-	if len(tds) == 0 {
-		return errors.New("no target Deployments found")
+	if len(tws) == 0 {
+		return errors.New("no target workloads found")
 	}
 
-	tcs := map[string]*kubernetes.Container{}
+	tcs := map[string][]*kubernetes.Container{}
 
-	for _, d := range tds {
-		c, err := d.DeployTargetContainer()
+	for _, w := range tws {
+		cs, err := w.DeployTargetContainers()
 		if err != nil {
-			return errors.Wrapf(err, "failed to retrieve deploy target container of Deployment %q", d.Name())
+			return errors.Wrapf(err, "failed to retrieve deploy target containers of %s %q", w.Kind(), w.Name())
 		}
 
-		tcs[d.Name()] = c
+		tcs[kubernetes.WorkloadKey(w)] = cs
 	}
 
-	for _, d := range tds {
-		fmt.Println("===== " + d.Name() + " =====")
+	for _, w := range tws {
+		fmt.Fprintln(out, "===== "+kubernetes.WorkloadKey(w)+" =====")
 
-		rs, err := client.ListReplicaSets(d)
+		rs, err := client.ListRevisions(w)
 		if err != nil {
-			return errors.Wrap(err, "failed to retrieve ReplicaSets")
+			return errors.Wrap(err, "failed to retrieve revisions")
 		}
 
-		lines := formatHistory(rs, tcs[d.Name()])
+		headers := historyHeaders(tcs[kubernetes.WorkloadKey(w)])
+		lines := formatHistory(rs, tcs[kubernetes.WorkloadKey(w)])
 		sort.Sort(sort.Reverse(sort.StringSlice(lines)))
 
 		if !historyOpts.all && len(lines) > defaultHistoryLimit {
 			lines = lines[0:defaultHistoryLimit]
 		}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		headers := []string{
-			"DEPLOYED AT",
-			"REVISION",
-			"USER",
-			"IMAGE",
-		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 		fmt.Fprintln(w, strings.Join(headers, "\t"))
 
 		for _, l := range lines {
@@ -96,17 +140,54 @@ func doHistory(cmd *cobra.Command, args []string) error {
 
 		w.Flush()
 
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
 	}
 
 	return nil
 }
 
-func formatHistory(rs []*kubernetes.ReplicaSet, container *kubernetes.Container) []string {
+// historyHeaders returns the headers of history table
+// IMAGE, TAG and DIGEST columns are shown for single container,
+// otherwise one image column is shown per container
+func historyHeaders(containers []*kubernetes.Container) []string {
+	headers := []string{
+		"DEPLOYED AT",
+		"REVISION",
+		"USER",
+	}
+
+	if len(containers) == 1 {
+		return append(headers, "IMAGE", "TAG", "DIGEST")
+	}
+
+	for _, c := range containers {
+		headers = append(headers, fmt.Sprintf("IMAGE (%s)", c.Name()))
+	}
+
+	return headers
+}
+
+func formatHistory(rs []kubernetes.Revision, containers []*kubernetes.Container) []string {
 	lines := make([]string, 0, len(rs))
 
 	for _, r := range rs {
-		lines = append(lines, strings.Join([]string{r.CreatedAt().String(), r.Revision(), r.DeployUser(), r.Images()[container.Name()]}, "\t"))
+		columns := []string{r.CreatedAt().String(), r.Revision(), r.DeployUser()}
+
+		if len(containers) == 1 {
+			image, tag, digest := r.Images()[containers[0].Name()], "", ""
+
+			if img, err := kubernetes.ParseImage(image); err == nil {
+				image, tag, digest = img.Name(), img.Tag, img.Digest
+			}
+
+			columns = append(columns, image, tag, digest)
+		} else {
+			for _, c := range containers {
+				columns = append(columns, r.Images()[c.Name()])
+			}
+		}
+
+		lines = append(lines, strings.Join(columns, "\t"))
 	}
 
 	return lines
@@ -116,5 +197,8 @@ func init() {
 	RootCmd.AddCommand(historyCmd)
 
 	historyCmd.Flags().BoolVarP(&historyOpts.all, "all", "a", false, fmt.Sprintf("Print all relases (default: recent %d items)", defaultHistoryLimit))
-	historyCmd.Flags().StringVarP(&historyOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace")
+	historyCmd.Flags().BoolVar(&historyOpts.allNamespaces, "all-namespaces", false, "view history of target workloads in all namespaces")
+	historyCmd.Flags().StringVarP(&historyOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace (or comma-separated namespaces)")
+	historyCmd.Flags().StringVarP(&historyOpts.selector, "selector", "l", "", "label selector to filter target workloads (e.g. role=web)")
+	historyCmd.Flags().BoolVar(&historyOpts.sequential, "sequential", false, "view history of clusters one by one, and stop at the first failure")
 }
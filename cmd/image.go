@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/dtan4/k8ship/kubernetes"
 	"github.com/pkg/errors"
@@ -17,11 +19,18 @@ var imageCmd = &cobra.Command{
 }
 
 var imageOpts = struct {
-	container  string
-	deployment string
-	dryRun     bool
-	namespace  string
-	user       string
+	accessToken    string
+	container      string
+	deployment     string
+	dryRun         bool
+	kind           string
+	logURL         string
+	namespace      string
+	pinDigest      bool
+	skipImageCheck bool
+	timeout        time.Duration
+	user           string
+	wait           bool
 }{}
 
 func doImage(cmd *cobra.Command, args []string) error {
@@ -30,55 +39,103 @@ func doImage(cmd *cobra.Command, args []string) error {
 	}
 	image := args[0]
 
-	client, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, rootOpts.context)
+	kubeContext, err := singleKubeContext()
+	if err != nil {
+		return err
+	}
+
+	client, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, kubeContext)
 	if err != nil {
 		return errors.Wrap(err, "failed to create Kubernetes client")
 	}
 
-	deployment, err := client.DetectTargetDeployment(imageOpts.namespace, imageOpts.deployment)
+	kind, err := kubernetes.ParseKind(imageOpts.kind)
+	if err != nil {
+		return err
+	}
+
+	workload, err := client.DetectTargetWorkload(imageOpts.namespace, kind, imageOpts.deployment)
 	if err != nil {
-		return errors.Wrap(err, "failed to detect target Deployment")
+		return errors.Wrapf(err, "failed to detect target %s", kind)
 	}
 
-	container, err := client.DetectTargetContainer(deployment, imageOpts.container)
+	container, err := client.DetectTargetContainer(workload, imageOpts.container)
 	if err != nil {
 		return errors.Wrap(err, "failed to detect target container")
 	}
 
+	if !imageOpts.skipImageCheck {
+		if err := checkImageExists(client, []kubernetes.Workload{workload}, image); err != nil {
+			return err
+		}
+	}
+
+	if imageOpts.pinDigest || workload.PinDigest() {
+		image, err = resolveImageDigest(client, []kubernetes.Workload{workload}, image)
+		if err != nil {
+			return err
+		}
+	}
+
 	if imageOpts.dryRun {
-		fmt.Printf("[dry-run] deploy to (deployment: %q, container: %q)\n", deployment.Name(), container.Name())
+		fmt.Printf("[dry-run] deploy to (%s: %q, container: %q)\n", strings.ToLower(workload.Kind()), workload.Name(), container.Name())
 		fmt.Printf("[dry-run]  before: %s\n", container.Image())
 		fmt.Printf("[dry-run]   after: %s\n", image)
 	} else {
-		fmt.Printf("deploy to (deployment: %q, container: %q)\n", deployment.Name(), container.Name())
+		ghDeployment, err := createGitHubDeploymentForImage(client, imageOpts.accessToken, workload, container.Name(), image, imageOpts.user, imageOpts.logURL)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("deploy to (%s: %q, container: %q)\n", strings.ToLower(workload.Kind()), workload.Name(), container.Name())
 		fmt.Printf("  before: %s\n", container.Image())
 		fmt.Printf("   after: %s\n", image)
 
-		if _, err := client.SetImage(
-			deployment, container.Name(), image, imageOpts.user, composeImageCause(image, container.Name(), deployment.Name(), tagOpts.namespace),
-		); err != nil {
-			return errors.Wrap(err, "failed to set image")
+		cause := composeImageCause(image, container.Name(), workload.Kind(), workload.Name(), imageOpts.namespace)
+
+		if _, err := setImageWithGitHubDeployment(client, ghDeployment, workload, container.Name(), image, imageOpts.user, cause, imageOpts.wait, imageOpts.timeout); err != nil {
+			return err
 		}
 
-		fmt.Printf("\n")
-		fmt.Printf("deployment successfully updated! check rollout status by `kubectl rollout status deployment/DEPLOYMENT --namespace %s`\n", imageOpts.namespace)
+		if imageOpts.wait {
+			fmt.Printf("\n")
+			fmt.Printf("%s successfully rolled out!\n", strings.ToLower(workload.Kind()))
+		} else {
+			fmt.Printf("%s successfully updated! check rollout status by `kubectl rollout status %s/%s --namespace %s`\n", strings.ToLower(workload.Kind()), strings.ToLower(workload.Kind()), workload.Name(), imageOpts.namespace)
+		}
 	}
 
 	return nil
 }
 
-func composeImageCause(image, container, deployment, namespace string) string {
-	return fmt.Sprintf(`k8ship image %s --container "%s" --deployment "%s" --namespace "%s"`, image, container, deployment, namespace)
+func composeImageCause(image, container, kind, name, namespace string) string {
+	var kindFlag string
+	if kind != kubernetes.KindDeployment {
+		kindFlag = fmt.Sprintf(` --kind "%s"`, kind)
+	}
+
+	return fmt.Sprintf(`k8ship image %s --container "%s" --deployment "%s"%s --namespace "%s"`, image, container, name, kindFlag, namespace)
 }
 
 func init() {
 	RootCmd.AddCommand(imageCmd)
 
+	imageCmd.Flags().StringVar(&imageOpts.accessToken, "access-token", "", "GitHub access token")
 	imageCmd.Flags().StringVarP(&imageOpts.container, "container", "c", "", "target container")
-	imageCmd.Flags().StringVarP(&imageOpts.deployment, "deployment", "d", "", "target Deployment")
+	imageCmd.Flags().StringVarP(&imageOpts.deployment, "deployment", "d", "", "name of target workload (Deployment by default, see --kind)")
 	imageCmd.Flags().BoolVar(&imageOpts.dryRun, "dry-run", false, "dry run")
+	imageCmd.Flags().StringVar(&imageOpts.kind, "kind", kubernetes.KindDeployment, "kind of target workload (Deployment, StatefulSet or DaemonSet)")
+	imageCmd.Flags().StringVar(&imageOpts.logURL, "log-url", "", "URL of deploy log attached to GitHub Deployment status")
 	imageCmd.Flags().StringVarP(&imageOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace")
+	imageCmd.Flags().BoolVar(&imageOpts.pinDigest, "pin-digest", false, "pin the image by digest of its manifest")
+	imageCmd.Flags().BoolVar(&imageOpts.skipImageCheck, "skip-image-check", false, "skip checking that the image exists in registry")
+	imageCmd.Flags().DurationVar(&imageOpts.timeout, "timeout", defaultRolloutTimeout, "timeout of waiting for rollout")
 	imageCmd.Flags().StringVarP(&imageOpts.user, "user", "u", "", "image tag to deploy (default: current login user)")
+	imageCmd.Flags().BoolVar(&imageOpts.wait, "wait", false, "wait for rollout to finish")
+
+	if imageOpts.accessToken == "" {
+		imageOpts.accessToken = os.Getenv("GITHUB_ACCESS_TOKEN")
+	}
 
 	if imageOpts.user == "" {
 		imageOpts.user = os.Getenv("USER")
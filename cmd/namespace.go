@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultConcurrency = 5
+)
+
+// targetNamespaces returns the namespaces to operate on
+// namespace may be comma-separated list. If all is true, namespaces which have the workloads
+// matched to the label selector (only deploy targets if targetOnly is true) are discovered across the cluster
+func targetNamespaces(k8sClient *kubernetes.Client, namespace string, all bool, selector string, targetOnly bool) ([]string, error) {
+	if !all {
+		namespaces := []string{}
+
+		for _, ns := range strings.Split(namespace, ",") {
+			if ns = strings.TrimSpace(ns); ns != "" && !containsString(namespaces, ns) {
+				namespaces = append(namespaces, ns)
+			}
+		}
+
+		if len(namespaces) == 0 {
+			return nil, errors.New("namespace must be specified")
+		}
+
+		return namespaces, nil
+	}
+
+	workloads, err := k8sClient.ListWorkloads(kubernetes.AllNamespaces(), selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve workloads")
+	}
+
+	namespaces := []string{}
+
+	for _, w := range workloads {
+		if targetOnly && !w.IsDeployTarget() {
+			continue
+		}
+
+		if !containsString(namespaces, w.Namespace()) {
+			namespaces = append(namespaces, w.Namespace())
+		}
+	}
+
+	if len(namespaces) == 0 {
+		return nil, errors.New("no target workloads found in any namespace")
+	}
+
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
+
+// printNamespaceHeader writes the header of output grouped by namespace
+func printNamespaceHeader(w io.Writer, namespace, prefix string) {
+	fmt.Fprintf(w, "%s##### namespace: %s #####\n", prefix, namespace)
+}
+
+// runConcurrently calls f with 0...n-1, running up to limit calls at the same time
+// Returned errors are indexed in the same way as f
+func runConcurrently(n, limit int, f func(i int) error) []error {
+	if limit < 1 {
+		limit = 1
+	}
+
+	errs := make([]error, n)
+	sem := make(chan struct{}, limit)
+	done := make(chan struct{})
+
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			sem <- struct{}{}
+			errs[i] = f(i)
+			<-sem
+			done <- struct{}{}
+		}(i)
+	}
+
+	for i := 0; i < n; i++ {
+		<-done
+	}
+
+	return errs
+}
+
+// namespaceFailures represents the errors keyed by namespace
+type namespaceFailures map[string]error
+
+func (f namespaceFailures) Error() string {
+	namespaces := make([]string, 0, len(f))
+
+	for ns := range f {
+		namespaces = append(namespaces, ns)
+	}
+
+	sort.Strings(namespaces)
+
+	messages := make([]string, 0, len(namespaces))
+
+	for _, ns := range namespaces {
+		messages = append(messages, fmt.Sprintf("namespace %s: %s", ns, f[ns]))
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// err returns the failures, or the error as it is if output is not grouped by namespace
+func (f namespaceFailures) err(grouped bool) error {
+	if !grouped && len(f) == 1 {
+		for _, err := range f {
+			return err
+		}
+	}
+
+	return f
+}
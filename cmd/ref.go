@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/dtan4/k8ship/github"
 	"github.com/dtan4/k8ship/kubernetes"
@@ -20,12 +20,24 @@ var refCmd = &cobra.Command{
 }
 
 var refOpts = struct {
-	accessToken string
-	container   string
-	deployment  string
-	dryRun      bool
-	namespace   string
-	user        string
+	accessToken    string
+	allowDowngrade bool
+	autoRollback   bool
+	commentPRs     bool
+	diff           bool
+	container      string
+	deployment     string
+	dryRun         bool
+	ignoreStatus   bool
+	logURL         string
+	namespace      string
+	pinDigest      bool
+	skipImageCheck bool
+	tagRelease     bool
+	timeout        time.Duration
+	user           string
+	wait           bool
+	waitForImage   time.Duration
 }{}
 
 func doRef(cmd *cobra.Command, args []string) error {
@@ -34,7 +46,20 @@ func doRef(cmd *cobra.Command, args []string) error {
 	}
 	ref := args[0]
 
-	k8sClient, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, rootOpts.context)
+	if err := checkCommentPullRequests(refOpts.commentPRs, refOpts.wait || refOpts.autoRollback); err != nil {
+		return err
+	}
+
+	if err := checkTagRelease(refOpts.tagRelease, refOpts.wait || refOpts.autoRollback); err != nil {
+		return err
+	}
+
+	kubeContext, err := singleKubeContext()
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, kubeContext)
 	if err != nil {
 		return errors.Wrap(err, "failed to create Kubernetes client")
 	}
@@ -49,14 +74,13 @@ func doRef(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "failed to detect target container")
 	}
 
-	repos, err := deployment.Repositories()
-	if err != nil {
-		return errors.Wrap(err, "failed to extract repositories from deployment")
+	targetContainers := map[string][]*kubernetes.Container{
+		kubernetes.WorkloadKey(deployment): {container},
 	}
 
-	repo, ok := repos[container.Name()]
-	if !ok {
-		return errors.Errorf("GitHub repository for container %q not found in deployment", container.Name())
+	repo, err := deployment.Repository(container.Name())
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve target repository")
 	}
 
 	ctx := context.Background()
@@ -67,44 +91,153 @@ func doRef(cmd *cobra.Command, args []string) error {
 		return errors.Wrapf(err, "failed to retrieve commit SHA-1 matched to ref %q in repo %q", ref, repo)
 	}
 
-	currentImage := deployment.ContainerImage(container.Name())
-	newImage := strings.Split(currentImage, ":")[0] + ":" + sha1
+	currentImage, err := kubernetes.ParseImage(deployment.ContainerImage(container.Name()))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse current image")
+	}
+
+	newImage := currentImage.WithTag(sha1).String()
+
+	if refOpts.waitForImage > 0 {
+		if err := waitForImage(k8sClient, []kubernetes.Workload{deployment}, newImage, refOpts.waitForImage); err != nil {
+			return err
+		}
+	} else if !refOpts.skipImageCheck {
+		if err := checkImageExists(k8sClient, []kubernetes.Workload{deployment}, newImage); err != nil {
+			return err
+		}
+	}
+
+	if err := checkDowngrade(ghClient, repo, runningCommits(targetContainers), sha1, refOpts.allowDowngrade); err != nil {
+		return err
+	}
+
+	if refOpts.ignoreStatus {
+		fmt.Printf("WARNING: commit status of %s is not checked because --ignore-status is given\n", sha1)
+	} else if err := checkCommitStatus(ghClient, repo, sha1, requiredContexts(k8sClient, []kubernetes.Workload{deployment})); err != nil {
+		return err
+	}
+
+	if refOpts.pinDigest || deployment.PinDigest() {
+		newImage, err = resolveImageDigest(k8sClient, []kubernetes.Workload{deployment}, newImage)
+		if err != nil {
+			return err
+		}
+	}
+
+	causeFlags := []string{}
+
+	if refOpts.allowDowngrade {
+		causeFlags = append(causeFlags, "--allow-downgrade")
+	}
+
+	if refOpts.ignoreStatus {
+		causeFlags = append(causeFlags, "--ignore-status")
+	}
+
+	cause := composeRefCause(ref, container.Name(), deployment.Name(), refOpts.namespace, causeFlags...)
+
+	var releaseTag string
+
+	if tagReleaseEnabled(refOpts.tagRelease) && (refOpts.wait || refOpts.autoRollback) {
+		releaseTag, err = releaseTagNameInCluster(k8sClient, []kubernetes.Workload{deployment}, refOpts.namespace, sha1, time.Now())
+		if err != nil {
+			return err
+		}
+	}
 
 	if refOpts.dryRun {
+		if refOpts.diff {
+			if err := printCommitDiff(ghClient, repo, runningCommits(targetContainers), sha1, "[dry-run] "); err != nil {
+				return err
+			}
+		}
+
+		if releaseTag != "" {
+			if err := tagRelease(ghClient, repo, releaseTag, sha1, true); err != nil {
+				return err
+			}
+		}
+
 		fmt.Printf("[dry-run] deploy to (deployment: %q, container: %q)\n", deployment.Name(), container.Name())
 		fmt.Printf("[dry-run]  before: %s\n", container.Image())
 		fmt.Printf("[dry-run]   after: %s\n", newImage)
 	} else {
+		ghDeployment, err := createGitHubDeploymentInCluster(k8sClient, ghClient, []kubernetes.Workload{deployment}, repo, ref, composeGitHubDeploymentDescription(ref, newImage, refOpts.user), refOpts.logURL, nil)
+		if err != nil {
+			return err
+		}
+
 		fmt.Printf("deploy to (deployment: %q, container: %q)\n", deployment.Name(), container.Name())
 		fmt.Printf("  before: %s\n", container.Image())
 		fmt.Printf("   after: %s\n", newImage)
 
-		if _, err := k8sClient.SetImage(
-			deployment, container.Name(), newImage, refOpts.user, composeRefCause(ref, container.Name(), deployment.Name(), refOpts.namespace),
-		); err != nil {
-			return errors.Wrap(err, "failed to set image")
+		newDeployment, err := setImageWithGitHubDeployment(
+			k8sClient, ghDeployment, deployment, container.Name(), newImage, refOpts.user, cause, refOpts.wait || refOpts.autoRollback, refOpts.timeout,
+		)
+		if err != nil {
+			if failures, ok := err.(rolloutFailures); ok && refOpts.autoRollback {
+				return rollbackWorkloads(
+					os.Stdout, k8sClient, []kubernetes.Workload{newDeployment}, targetContainers, failures, refOpts.user, cause,
+				)
+			}
+
+			return err
 		}
 
-		fmt.Printf("\n")
-		fmt.Printf("deployment successfully updated! check rollout status by `kubectl rollout status deployment/DEPLOYMENT --namespace %s`\n", refOpts.namespace)
+		if refOpts.wait || refOpts.autoRollback {
+			fmt.Printf("\n")
+			fmt.Println("deployment successfully rolled out!")
+		} else {
+			fmt.Printf("deployment successfully updated! check rollout status by `kubectl rollout status deployment/DEPLOYMENT --namespace %s`\n", refOpts.namespace)
+		}
+
+		if releaseTag != "" {
+			if err := tagRelease(ghClient, repo, releaseTag, sha1, false); err != nil {
+				return err
+			}
+		}
+
+		if commentPullRequestsEnabled(refOpts.commentPRs) && (refOpts.wait || refOpts.autoRollback) {
+			commentOnPullRequests(k8sClient, ghClient, []kubernetes.Workload{deployment}, repo, runningCommits(targetContainers), sha1, refOpts.namespace, refOpts.user)
+		}
 	}
 
 	return nil
 }
 
-func composeRefCause(ref, container, deployment, namespace string) string {
-	return fmt.Sprintf(`k8ship ref %s --container "%s" --deployment "%s" --namespace "%s"`, ref, container, deployment, namespace)
+func composeRefCause(ref, container, deployment, namespace string, flags ...string) string {
+	cause := fmt.Sprintf(`k8ship ref %s --container "%s" --deployment "%s" --namespace "%s"`, ref, container, deployment, namespace)
+
+	for _, f := range flags {
+		cause += " " + f
+	}
+
+	return cause
 }
 
 func init() {
 	RootCmd.AddCommand(refCmd)
 
 	refCmd.Flags().StringVar(&refOpts.accessToken, "access-token", "", "GitHub access token")
+	refCmd.Flags().BoolVar(&refOpts.allowDowngrade, "allow-downgrade", false, "deploy even if the commit is behind the running one")
+	refCmd.Flags().BoolVar(&refOpts.autoRollback, "auto-rollback", false, "roll back automatically if rollout fails (implies --wait)")
+	refCmd.Flags().BoolVar(&refOpts.commentPRs, "comment-pull-requests", false, "comment on pull requests merged since running revision after rollout (requires --wait)")
 	refCmd.Flags().StringVarP(&refOpts.container, "container", "c", "", "target container")
 	refCmd.Flags().StringVarP(&refOpts.deployment, "deployment", "d", "", "target Deployment")
+	refCmd.Flags().BoolVar(&refOpts.diff, "diff", false, "show commits between running and target revision in dry run")
 	refCmd.Flags().BoolVar(&refOpts.dryRun, "dry-run", false, "dry run")
+	refCmd.Flags().BoolVar(&refOpts.ignoreStatus, "ignore-status", false, "deploy even if commit status or check runs are not successful")
+	refCmd.Flags().StringVar(&refOpts.logURL, "log-url", "", "URL of deploy log attached to GitHub Deployment status")
 	refCmd.Flags().StringVarP(&refOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace")
+	refCmd.Flags().BoolVar(&refOpts.pinDigest, "pin-digest", false, "pin the image by digest of its manifest")
+	refCmd.Flags().BoolVar(&refOpts.skipImageCheck, "skip-image-check", false, "skip checking that the image exists in registry")
+	refCmd.Flags().BoolVar(&refOpts.tagRelease, "tag-release", false, "create tag at the deployed commit on GitHub after rollout (requires --wait)")
+	refCmd.Flags().DurationVar(&refOpts.timeout, "timeout", defaultRolloutTimeout, "timeout of waiting for rollout")
 	refCmd.Flags().StringVarP(&refOpts.user, "user", "u", "", "image tag to deploy (default: current login user)")
+	refCmd.Flags().BoolVar(&refOpts.wait, "wait", false, "wait for rollout to finish")
+	refCmd.Flags().DurationVar(&refOpts.waitForImage, "wait-for-image", 0, "wait for the image to be pushed to registry up to the given duration")
+	refCmd.Flags().Lookup("wait-for-image").NoOptDefVal = defaultWaitForImageTimeout.String()
 
 	if refOpts.accessToken == "" {
 		refOpts.accessToken = os.Getenv("GITHUB_ACCESS_TOKEN")
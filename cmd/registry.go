@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/dtan4/k8ship/registry"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultWaitForImageTimeout = 10 * time.Minute
+	imagePollInitialInterval   = 5 * time.Second
+	imagePollMaxInterval       = 30 * time.Second
+)
+
+// checkImageExists returns error if the manifest of the given image does not exist in its registry
+// Credentials are read from imagePullSecrets of the given workloads
+func checkImageExists(k8sClient *kubernetes.Client, workloads []kubernetes.Workload, image string) error {
+	img, client, err := newImageRegistryClient(k8sClient, workloads, image)
+	if err != nil {
+		return err
+	}
+
+	exists, err := client.ManifestExists(img.Registry, img.Repository, imageReference(img))
+	if err != nil {
+		return errors.Wrapf(err, "failed to check image %q in registry", image)
+	}
+
+	if !exists {
+		return errors.Errorf("image %q does not exist in registry, use --skip-image-check to deploy anyway", image)
+	}
+
+	return nil
+}
+
+// waitForImage blocks until the manifest of the given image appears in its registry
+// Registry is polled with exponential backoff, and error is returned on timeout.
+// Transient errors and errors of the repository which is not created yet are retried until timeout
+func waitForImage(k8sClient *kubernetes.Client, workloads []kubernetes.Workload, image string, timeout time.Duration) error {
+	img, client, err := newImageRegistryClient(k8sClient, workloads, image)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	interval := imagePollInitialInterval
+
+	fmt.Printf("waiting for image %s to be pushed...\n", image)
+
+	for {
+		exists, err := client.ManifestExists(img.Registry, img.Repository, imageReference(img))
+		if err != nil && !registry.IsRetryable(err) {
+			return errors.Wrapf(err, "failed to check image %q in registry", image)
+		}
+
+		if exists {
+			fmt.Printf("  image %s found\n", image)
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			if err != nil {
+				return errors.Wrapf(err, "image %q did not appear in registry within %s", image, timeout)
+			}
+
+			return errors.Errorf("image %q did not appear in registry within %s", image, timeout)
+		}
+
+		if interval > remaining {
+			interval = remaining
+		}
+
+		if err != nil {
+			fmt.Printf("  %s, retrying in %s\n", err, interval)
+		} else {
+			fmt.Printf("  not found yet, retrying in %s\n", interval)
+		}
+
+		time.Sleep(interval)
+
+		interval *= 2
+		if interval > imagePollMaxInterval {
+			interval = imagePollMaxInterval
+		}
+	}
+}
+
+// resolveImageDigest returns the given image pinned by the digest of its manifest
+// e.g. `dtan4/app:v1` -> `dtan4/app:v1@sha256:...`
+func resolveImageDigest(k8sClient *kubernetes.Client, workloads []kubernetes.Workload, image string) (string, error) {
+	img, client, err := newImageRegistryClient(k8sClient, workloads, image)
+	if err != nil {
+		return "", err
+	}
+
+	if img.Digest != "" {
+		return image, nil
+	}
+
+	digest, err := client.ManifestDigest(img.Registry, img.Repository, imageReference(img))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve digest of image %q", image)
+	}
+
+	img.Digest = digest
+
+	return img.String(), nil
+}
+
+// newImageRegistryClient parses the given image and creates registry client
+// with the credentials in imagePullSecrets of the given workloads
+func newImageRegistryClient(k8sClient *kubernetes.Client, workloads []kubernetes.Workload, image string) (*kubernetes.Image, *registry.Client, error) {
+	img, err := kubernetes.ParseImage(image)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse image %q", image)
+	}
+
+	credentials := map[string]registry.Credential{}
+
+	for _, w := range workloads {
+		configs, err := k8sClient.DockerConfigs(w)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to retrieve imagePullSecrets of %s %q", w.Kind(), w.Name())
+		}
+
+		for _, c := range configs {
+			cs, err := registry.ParseDockerConfig(c)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to parse imagePullSecrets of %s %q", w.Kind(), w.Name())
+			}
+
+			for host, cred := range cs {
+				credentials[host] = cred
+			}
+		}
+	}
+
+	return img, registry.NewClient(credentials), nil
+}
+
+// imageReference returns the digest, tag or "latest" of the given image in this order
+func imageReference(img *kubernetes.Image) string {
+	if img.Digest != "" {
+		return img.Digest
+	}
+
+	if img.Tag != "" {
+		return img.Tag
+	}
+
+	return "latest"
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dtan4/k8ship/github"
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	releaseNotesPayloadKey = "release_notes"
+)
+
+// releaseNotesCmd represents the release-notes command
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes BRANCH|COMMIT_SHA1",
+	Short: "Generate release notes from pull requests merged since running revision",
+	RunE:  doReleaseNotes,
+}
+
+var releaseNotesOpts = struct {
+	accessToken   string
+	container     string
+	createRelease bool
+	deployment    string
+	namespace     string
+	repo          string
+}{}
+
+func doReleaseNotes(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("ref (branch, full commit SHA-1 or short commit SHA-1) must be given")
+	}
+	ref := args[0]
+
+	kubeContext, err := singleKubeContext()
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, kubeContext)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Kubernetes client")
+	}
+
+	targetWorkloads, targetContainers, repo, err := detectTargets(k8sClient, releaseNotesOpts.namespace, releaseNotesOpts.deployment, releaseNotesOpts.container, releaseNotesOpts.repo)
+	if err != nil {
+		return err
+	}
+
+	ghClient := github.NewClient(context.Background(), releaseNotesOpts.accessToken)
+
+	sha1, err := ghClient.CommitFronRef(repo, ref)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve commit SHA-1 matched to ref %q in repo %q", ref, repo)
+	}
+
+	notes, err := composeReleaseNotes(ghClient, repo, runningCommits(targetContainers), sha1)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(notes)
+
+	if releaseNotesOpts.createRelease {
+		tag, err := releaseTagNameInCluster(k8sClient, targetWorkloads, releaseNotesOpts.namespace, sha1, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := createRelease(ghClient, repo, tag, sha1, notes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// composeReleaseNotes renders the pull requests merged between the running commits and the target commit
+// as markdown
+func composeReleaseNotes(client *github.Client, repo string, running []string, target string) (string, error) {
+	var buf bytes.Buffer
+
+	numbers, err := mergedPullRequests(client, repo, running, target)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(&buf, "## %s %s\n\n", repo, shortSHA(target))
+
+	for _, base := range running {
+		if base != target {
+			fmt.Fprintf(&buf, "https://github.com/%s/compare/%s...%s\n\n", repo, base, target)
+		}
+	}
+
+	if len(numbers) == 0 {
+		fmt.Fprintf(&buf, "No pull request merged.\n")
+		return buf.String(), nil
+	}
+
+	for _, n := range numbers {
+		pr, err := client.PullRequest(repo, n)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to retrieve pull request #%d in repo %q", n, repo)
+		}
+
+		fmt.Fprintf(&buf, "- #%d %s (@%s)", pr.Number, pr.Title, pr.Author)
+
+		if len(pr.Labels) > 0 {
+			fmt.Fprintf(&buf, " `%s`", strings.Join(pr.Labels, "` `"))
+		}
+
+		fmt.Fprintf(&buf, "\n")
+	}
+
+	return buf.String(), nil
+}
+
+// createRelease publishes the release notes as GitHub Release with the given tag
+func createRelease(client *github.Client, repo, tag, sha1, notes string) error {
+	url, err := client.CreateRelease(repo, tag, sha1, tag, notes)
+	if err != nil {
+		return errors.Wrap(err, "failed to create GitHub Release")
+	}
+
+	fmt.Printf("GitHub Release: %s\n", url)
+
+	return nil
+}
+
+// shortSHA returns the first 7 characters of commit SHA-1
+func shortSHA(sha1 string) string {
+	if len(sha1) < 7 {
+		return sha1
+	}
+
+	return sha1[0:7]
+}
+
+func init() {
+	RootCmd.AddCommand(releaseNotesCmd)
+
+	releaseNotesCmd.Flags().StringVar(&releaseNotesOpts.accessToken, "access-token", "", "GitHub access token")
+	releaseNotesCmd.Flags().StringVarP(&releaseNotesOpts.container, "container", "c", "", "target container")
+	releaseNotesCmd.Flags().BoolVar(&releaseNotesOpts.createRelease, "create-release", false, "publish the release notes as GitHub Release")
+	releaseNotesCmd.Flags().StringVarP(&releaseNotesOpts.deployment, "deployment", "d", "", "target Deployment")
+	releaseNotesCmd.Flags().StringVarP(&releaseNotesOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesOpts.repo, "repo", "", "target GitHub repository (owner/name)")
+
+	if releaseNotesOpts.accessToken == "" {
+		releaseNotesOpts.accessToken = os.Getenv("GITHUB_ACCESS_TOKEN")
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -13,90 +14,285 @@ import (
 // reloadCmd represents the reload command
 var reloadCmd = &cobra.Command{
 	Use:   "reload",
-	Short: "Reload Pods in Deployment",
+	Short: "Reload Pods in Deployment, StatefulSet or DaemonSet",
 	RunE:  doReload,
 }
 
 var reloadOpts = struct {
-	all        bool
-	deployment string
-	dryRun     bool
-	namespace  string
-	user       string
+	all           bool
+	allNamespaces bool
+	concurrency   int
+	deployment    string
+	dryRun        bool
+	kind          string
+	namespace     string
+	selector      string
+	sequential    bool
+	timeout       time.Duration
+	user          string
+	wait          bool
 }{}
 
 func doReload(cmd *cobra.Command, args []string) error {
-	k8sClient, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, rootOpts.context)
-	if err != nil {
-		return errors.Wrap(err, "failed to create Kubernetes client")
+	if reloadOpts.deployment != "" && reloadOpts.selector != "" {
+		return errors.New("both Deployment and selector cannot be specified simultaneously")
+	}
+
+	if reloadOpts.deployment != "" && reloadOpts.allNamespaces {
+		return errors.New("Deployment cannot be specified with --all-namespaces")
 	}
 
-	var deployments []*kubernetes.Deployment
+	if reloadOpts.all && reloadOpts.allNamespaces {
+		return errors.New("--all cannot be specified with --all-namespaces, list the target namespaces with --namespace")
+	}
+
+	if reloadOpts.concurrency < 1 {
+		return errors.New("concurrency must be greater than 0")
+	}
 
-	if reloadOpts.deployment == "" {
-		ds, err := k8sClient.ListDeployments(reloadOpts.namespace)
+	if reloadOpts.kind != "" {
+		kind, err := kubernetes.ParseKind(reloadOpts.kind)
 		if err != nil {
-			return errors.Wrap(err, "failed to retrieve Deployments")
+			return err
 		}
 
-		if len(ds) == 0 {
-			return errors.Errorf("no Deployment found in namespace %s", reloadOpts.namespace)
-		}
+		reloadOpts.kind = kind
+	}
 
-		if reloadOpts.all {
-			deployments = ds
-		} else {
-			tds := []*kubernetes.Deployment{}
+	contexts := kubeContexts()
+	timestamp := time.Now().Local().String()
 
-			for _, d := range ds {
-				if d.IsDeployTarget() {
-					tds = append(tds, d)
-				}
+	if reloadOpts.sequential || len(contexts) == 1 {
+		errs := runClustersSequentially(contexts, func(i int, w io.Writer) error {
+			c, err := planClusterReload(contexts[i])
+			if err != nil {
+				return err
 			}
 
-			if len(tds) == 0 {
-				return errors.New("no target Deployments found")
+			if reloadOpts.dryRun {
+				c.printDryRun()
+				return nil
 			}
 
-			deployments = tds
-		}
-	} else {
-		d, err := k8sClient.GetDeployment(reloadOpts.namespace, reloadOpts.deployment)
+			return c.execute(w, timestamp)
+		})
+
+		return clusterResults(contexts, errs)
+	}
+
+	clusters := make([]*clusterReload, 0, len(contexts))
+
+	for _, ctx := range contexts {
+		c, err := planClusterReload(ctx)
 		if err != nil {
-			return errors.Wrapf(err, "failed to retrieve Deployment %s in %s", reloadOpts.deployment, reloadOpts.namespace)
+			return errors.Wrapf(err, "failed to prepare reload in context %s", ctx)
 		}
 
-		deployments = []*kubernetes.Deployment{d}
+		clusters = append(clusters, c)
 	}
 
-	timestamp := time.Now().Local().String()
-
 	if reloadOpts.dryRun {
-		for _, d := range deployments {
-			fmt.Printf("[dry-run] reloaded all Pods in %s\n", d.Name())
+		for _, c := range clusters {
+			printContextHeader(os.Stdout, c.context)
+			c.printDryRun()
+			fmt.Printf("\n")
 		}
-	} else {
-		for _, d := range deployments {
-			_, err := k8sClient.ReloadPods(d, reloadOpts.user, timestamp)
+
+		return nil
+	}
+
+	errs := runClustersInParallel(contexts, func(i int, w io.Writer) error {
+		return clusters[i].execute(w, timestamp)
+	})
+
+	return clusterResults(contexts, errs)
+}
+
+// clusterReload represents the reload of workloads in one Kubernetes cluster
+type clusterReload struct {
+	context       string
+	k8sClient     *kubernetes.Client
+	namespaces    []string
+	showNamespace bool
+	targets       [][]kubernetes.Workload
+}
+
+// planClusterReload detects the workloads to reload in the cluster of the given context
+func planClusterReload(kubeContext string) (*clusterReload, error) {
+	k8sClient, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, kubeContext)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Kubernetes client")
+	}
+
+	namespaces, err := targetNamespaces(k8sClient, reloadOpts.namespace, reloadOpts.allNamespaces, reloadOpts.selector, !reloadOpts.all)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &clusterReload{
+		context:       kubeContext,
+		k8sClient:     k8sClient,
+		namespaces:    namespaces,
+		showNamespace: reloadOpts.allNamespaces || len(namespaces) > 1,
+		targets:       make([][]kubernetes.Workload, len(namespaces)),
+	}
+
+	for i, ns := range namespaces {
+		c.targets[i], err = reloadTargetWorkloads(k8sClient, ns)
+		if err != nil {
+			if c.showNamespace {
+				return nil, errors.Wrapf(err, "failed to detect target workloads in namespace %s", ns)
+			}
+
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// printDryRun prints the workloads to reload
+func (c *clusterReload) printDryRun() {
+	for i, ns := range c.namespaces {
+		if c.showNamespace {
+			printNamespaceHeader(os.Stdout, ns, "[dry-run] ")
+		}
+
+		for _, w := range c.targets[i] {
+			fmt.Printf("[dry-run] reloaded all Pods in %s\n", kubernetes.WorkloadKey(w))
+		}
+	}
+}
+
+// execute reloads the target workloads and waits for their rollouts if --wait is given
+// Progress is written to w, because this may run concurrently with other clusters
+func (c *clusterReload) execute(w io.Writer, timestamp string) error {
+	reloadedWorkloads := make([][]kubernetes.Workload, len(c.namespaces))
+
+	errs := runConcurrently(len(c.namespaces), reloadOpts.concurrency, func(i int) error {
+		for _, wl := range c.targets[i] {
+			newwl, err := c.k8sClient.ReloadPods(wl, reloadOpts.user, timestamp)
 			if err != nil {
 				return errors.Wrap(err, "failed to set annotations")
 			}
 
-			fmt.Printf("reloaded all Pods in %s\n", d.Name())
+			reloadedWorkloads[i] = append(reloadedWorkloads[i], newwl)
+		}
+
+		return nil
+	})
+
+	failures := namespaceFailures{}
+
+	for i, ns := range c.namespaces {
+		if c.showNamespace {
+			printNamespaceHeader(w, ns, "")
+		}
+
+		for _, wl := range reloadedWorkloads[i] {
+			fmt.Fprintf(w, "reloaded all Pods in %s\n", kubernetes.WorkloadKey(wl))
+		}
+
+		if errs[i] != nil {
+			failures[ns] = errs[i]
+		}
+	}
+
+	if len(failures) > 0 {
+		return failures.err(c.showNamespace)
+	}
+
+	if reloadOpts.wait {
+		deadline := time.Now().Add(reloadOpts.timeout)
+
+		for i, ns := range c.namespaces {
+			fmt.Fprintf(w, "\n")
+
+			if c.showNamespace {
+				printNamespaceHeader(w, ns, "")
+			}
+
+			if err := waitForRollouts(w, c.k8sClient, reloadedWorkloads[i], time.Until(deadline)); err != nil {
+				failures[ns] = err
+			}
+		}
+
+		if len(failures) > 0 {
+			return failures.err(c.showNamespace)
 		}
 	}
 
 	return nil
 }
 
+// reloadTargetWorkloads returns the workloads to reload in the namespace
+// -d and --all target Deployments unless --kind is given.
+// --all does not reload StatefulSets and DaemonSets by default, because restarting all of them at once may disrupt the service
+func reloadTargetWorkloads(k8sClient *kubernetes.Client, namespace string) ([]kubernetes.Workload, error) {
+	kind := reloadOpts.kind
+
+	if reloadOpts.deployment != "" || reloadOpts.all {
+		if kind == "" {
+			kind = kubernetes.KindDeployment
+		}
+	}
+
+	if reloadOpts.deployment != "" {
+		w, err := k8sClient.GetWorkload(namespace, kind, reloadOpts.deployment)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve %s %s in %s", kind, reloadOpts.deployment, namespace)
+		}
+
+		return []kubernetes.Workload{w}, nil
+	}
+
+	ws, err := k8sClient.ListWorkloads(namespace, reloadOpts.selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve workloads")
+	}
+
+	if len(ws) == 0 {
+		return nil, noWorkloadError(namespace, reloadOpts.selector)
+	}
+
+	tws := []kubernetes.Workload{}
+
+	for _, w := range ws {
+		if kind != "" && w.Kind() != kind {
+			continue
+		}
+
+		if reloadOpts.all || w.IsDeployTarget() {
+			tws = append(tws, w)
+		}
+	}
+
+	if reloadOpts.all && len(tws) == 0 {
+		return nil, errors.Errorf("no %s found in namespace %s", kind, namespace)
+	}
+
+	if len(tws) == 0 {
+		return nil, errors.New("no target workloads found")
+	}
+
+	return tws, nil
+}
+
 func init() {
 	RootCmd.AddCommand(reloadCmd)
 
 	reloadCmd.Flags().BoolVarP(&reloadOpts.all, "all", "a", false, "reload all Deployments")
-	reloadCmd.Flags().StringVarP(&reloadOpts.deployment, "deployment", "d", "", "target Deployment")
+	reloadCmd.Flags().BoolVar(&reloadOpts.allNamespaces, "all-namespaces", false, "reload workloads in all namespaces")
+	reloadCmd.Flags().IntVar(&reloadOpts.concurrency, "concurrency", defaultConcurrency, "maximum number of namespaces reloaded at the same time")
+	reloadCmd.Flags().StringVarP(&reloadOpts.deployment, "deployment", "d", "", "name of target workload (Deployment by default, see --kind)")
 	reloadCmd.Flags().BoolVar(&reloadOpts.dryRun, "dry-run", false, "dry run")
-	reloadCmd.Flags().StringVarP(&reloadOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace")
+	reloadCmd.Flags().StringVar(&reloadOpts.kind, "kind", "", "kind of target workloads (Deployment, StatefulSet or DaemonSet)")
+	reloadCmd.Flags().StringVarP(&reloadOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace (or comma-separated namespaces)")
+	reloadCmd.Flags().StringVarP(&reloadOpts.selector, "selector", "l", "", "label selector to filter target workloads (e.g. role=web)")
+	reloadCmd.Flags().BoolVar(&reloadOpts.sequential, "sequential", false, "reload clusters one by one, and stop at the first failure")
+	reloadCmd.Flags().DurationVar(&reloadOpts.timeout, "timeout", defaultRolloutTimeout, "timeout of waiting for rollout")
 	reloadCmd.Flags().StringVarP(&reloadOpts.user, "user", "u", "", "image tag to deploy (default: current login user)")
+	reloadCmd.Flags().BoolVar(&reloadOpts.wait, "wait", false, "wait for rollout to finish")
 
 	if reloadOpts.user == "" {
 		reloadOpts.user = os.Getenv("USER")
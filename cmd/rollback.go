@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll back to the previous (or the given) revision",
+	RunE:  doRollback,
+}
+
+var rollbackOpts = struct {
+	deployment string
+	dryRun     bool
+	kind       string
+	namespace  string
+	timeout    time.Duration
+	toRevision int64
+	user       string
+	wait       bool
+}{}
+
+func doRollback(cmd *cobra.Command, args []string) error {
+	if rollbackOpts.toRevision < 0 {
+		return errors.Errorf("invalid revision %d", rollbackOpts.toRevision)
+	}
+
+	kubeContext, err := singleKubeContext()
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, kubeContext)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Kubernetes client")
+	}
+
+	targetWorkloads, err := rollbackTargetWorkloads(k8sClient)
+	if err != nil {
+		return err
+	}
+
+	if rollbackOpts.toRevision > 0 && len(targetWorkloads) > 1 {
+		return errors.New("--to-revision requires single target workload because revision numbers differ by workload, specify it with -d (and --kind)")
+	}
+
+	targetContainers := map[string][]*kubernetes.Container{}
+	targetRevisions := map[string]kubernetes.Revision{}
+
+	for _, w := range targetWorkloads {
+		key := kubernetes.WorkloadKey(w)
+
+		cs, err := w.DeployTargetContainers()
+		if err != nil {
+			return errors.Wrapf(err, "failed to retrieve deploy target containers of %s %q", w.Kind(), w.Name())
+		}
+
+		targetContainers[key] = cs
+
+		rs, err := k8sClient.ListRevisions(w)
+		if err != nil {
+			return errors.Wrap(err, "failed to retrieve revisions")
+		}
+
+		r, err := kubernetes.FindRollbackRevision(rs, rollbackOpts.toRevision)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find revision to roll back in %s %q", w.Kind(), w.Name())
+		}
+
+		for _, c := range cs {
+			if _, ok := r.Images()[c.Name()]; !ok {
+				return errors.Errorf("container %q does not exist in revision %s of %s %q", c.Name(), r.Revision(), w.Kind(), w.Name())
+			}
+		}
+
+		targetRevisions[key] = r
+	}
+
+	if rollbackOpts.dryRun {
+		for _, w := range targetWorkloads {
+			r := targetRevisions[kubernetes.WorkloadKey(w)]
+
+			for _, c := range targetContainers[kubernetes.WorkloadKey(w)] {
+				fmt.Printf("[dry-run] roll back to revision %s (%s: %q, container: %q)\n", r.Revision(), strings.ToLower(w.Kind()), w.Name(), c.Name())
+				fmt.Printf("[dry-run]   before: %s\n", c.Image())
+				fmt.Printf("[dry-run]   after:  %s\n", r.Images()[c.Name()])
+			}
+		}
+	} else {
+		for _, w := range targetWorkloads {
+			r := targetRevisions[kubernetes.WorkloadKey(w)]
+
+			for _, c := range targetContainers[kubernetes.WorkloadKey(w)] {
+				fmt.Printf("roll back to revision %s (%s: %q, container: %q)\n", r.Revision(), strings.ToLower(w.Kind()), w.Name(), c.Name())
+				fmt.Printf("  before: %s\n", c.Image())
+				fmt.Printf("  after:  %s\n", r.Images()[c.Name()])
+			}
+		}
+
+		updatedWorkloads := make([]kubernetes.Workload, 0, len(targetWorkloads))
+
+		for _, w := range targetWorkloads {
+			r := targetRevisions[kubernetes.WorkloadKey(w)]
+			images := map[string]string{}
+
+			for _, c := range targetContainers[kubernetes.WorkloadKey(w)] {
+				images[c.Name()] = r.Images()[c.Name()]
+			}
+
+			neww, err := k8sClient.SetImage(
+				w, images, rollbackOpts.user, composeRollbackCause(r.Revision(), w.Kind(), rollbackOpts.deployment, rollbackOpts.namespace),
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to set image")
+			}
+
+			updatedWorkloads = append(updatedWorkloads, neww)
+		}
+
+		fmt.Printf("\n")
+
+		if rollbackOpts.wait {
+			if err := waitForRollouts(os.Stdout, k8sClient, updatedWorkloads, rollbackOpts.timeout); err != nil {
+				return err
+			}
+
+			fmt.Printf("\n")
+			fmt.Println("deployments successfully rolled back!")
+		} else {
+			fmt.Printf("deployments successfully rolled back! check rollout status by `kubectl rollout status KIND/NAME --namespace %s`\n", rollbackOpts.namespace)
+		}
+	}
+
+	return nil
+}
+
+// rollbackTargetWorkloads returns the workload given by -d, or all deploy target workloads in the namespace
+func rollbackTargetWorkloads(k8sClient *kubernetes.Client) ([]kubernetes.Workload, error) {
+	kind, err := kubernetes.ParseKind(rollbackOpts.kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if rollbackOpts.deployment != "" {
+		w, err := k8sClient.GetWorkload(rollbackOpts.namespace, kind, rollbackOpts.deployment)
+		if err != nil {
+			return nil, err
+		}
+
+		return []kubernetes.Workload{w}, nil
+	}
+
+	workloads, err := k8sClient.ListWorkloads(rollbackOpts.namespace, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve workloads")
+	}
+
+	if len(workloads) == 0 {
+		return nil, errors.Errorf("no workload found in namespace %s", rollbackOpts.namespace)
+	}
+
+	targetWorkloads := []kubernetes.Workload{}
+
+	for _, w := range workloads {
+		if w.IsDeployTarget() {
+			targetWorkloads = append(targetWorkloads, w)
+		}
+	}
+
+	if len(targetWorkloads) == 0 {
+		return nil, errors.New("no target workloads found")
+	}
+
+	return targetWorkloads, nil
+}
+
+func composeRollbackCause(revision, kind, name, namespace string) string {
+	if name == "" {
+		return fmt.Sprintf(`k8ship rollback --to-revision %s --namespace "%s"`, revision, namespace)
+	}
+
+	var kindFlag string
+	if kind != kubernetes.KindDeployment {
+		kindFlag = fmt.Sprintf(` --kind "%s"`, kind)
+	}
+
+	return fmt.Sprintf(`k8ship rollback --to-revision %s --deployment "%s"%s --namespace "%s"`, revision, name, kindFlag, namespace)
+}
+
+func init() {
+	RootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().StringVarP(&rollbackOpts.deployment, "deployment", "d", "", "name of target workload (Deployment by default, see --kind)")
+	rollbackCmd.Flags().BoolVar(&rollbackOpts.dryRun, "dry-run", false, "dry run")
+	rollbackCmd.Flags().StringVar(&rollbackOpts.kind, "kind", kubernetes.KindDeployment, "kind of target workload (Deployment, StatefulSet or DaemonSet)")
+	rollbackCmd.Flags().StringVarP(&rollbackOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace")
+	rollbackCmd.Flags().DurationVar(&rollbackOpts.timeout, "timeout", defaultRolloutTimeout, "timeout of waiting for rollout")
+	rollbackCmd.Flags().Int64Var(&rollbackOpts.toRevision, "to-revision", 0, "revision to roll back to (default: previous revision)")
+	rollbackCmd.Flags().StringVarP(&rollbackOpts.user, "user", "u", "", "image tag to deploy (default: current login user)")
+	rollbackCmd.Flags().BoolVar(&rollbackOpts.wait, "wait", false, "wait for rollout to finish")
+
+	if rollbackOpts.user == "" {
+		rollbackOpts.user = os.Getenv("USER")
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dtan4/k8ship/kubernetes"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRolloutTimeout = 5 * time.Minute
+)
+
+// rolloutFailures represents the rollout errors keyed by kubernetes.WorkloadKey
+type rolloutFailures map[string]error
+
+func (f rolloutFailures) Error() string {
+	names := make([]string, 0, len(f))
+
+	for name := range f {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+
+	for _, name := range names {
+		messages = append(messages, f[name].Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// waitForRollouts blocks until the rollouts of all given workloads finish
+// timeout is shared by all workloads
+// returned error is rolloutFailures if any rollout fails
+// progress is written to w
+func waitForRollouts(w io.Writer, client *kubernetes.Client, workloads []kubernetes.Workload, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	failures := rolloutFailures{}
+
+	for _, wl := range workloads {
+		fmt.Fprintf(w, "waiting for rollout of %s %q...\n", strings.ToLower(wl.Kind()), wl.Name())
+
+		if _, err := client.WaitForRollout(wl, time.Until(deadline), func(message string) {
+			fmt.Fprintf(w, "  %s\n", message)
+		}); err != nil {
+			fmt.Fprintf(w, "  %s\n", err)
+			failures[kubernetes.WorkloadKey(wl)] = err
+		}
+	}
+
+	if len(failures) > 0 {
+		return failures
+	}
+
+	return nil
+}
+
+// rollbackWorkloads restores the images before deploy to all given workloads
+// containers must hold the target containers before deploy, keyed by kubernetes.WorkloadKey
+func rollbackWorkloads(w io.Writer, client *kubernetes.Client, workloads []kubernetes.Workload, containers map[string][]*kubernetes.Container, failures rolloutFailures, user, cause string) error {
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintln(w, "rollout failed, rolling back all target workloads...")
+
+	if err := revertWorkloads(w, client, workloads, containers, failures, "rolled back together with failed workloads", user, cause); err != nil {
+		return err
+	}
+
+	return errors.Wrapf(failures, "%d workloads were rolled back", len(workloads))
+}
+
+// revertWorkloads sets the images before deploy to all given workloads
+// reason is printed for the workloads which are not in failures
+func revertWorkloads(w io.Writer, client *kubernetes.Client, workloads []kubernetes.Workload, containers map[string][]*kubernetes.Container, failures rolloutFailures, reason, user, cause string) error {
+	for _, wl := range workloads {
+		cs := containers[kubernetes.WorkloadKey(wl)]
+
+		if _, err := client.SetImage(wl, containerImages(cs), user, composeAutoRollbackCause(cause)); err != nil {
+			return errors.Wrapf(err, "failed to roll back %s %q", wl.Kind(), wl.Name())
+		}
+
+		r := reason
+		if err, ok := failures[kubernetes.WorkloadKey(wl)]; ok {
+			r = err.Error()
+		}
+
+		for _, c := range cs {
+			fmt.Fprintf(w, "rolled back (%s: %q, container: %q)\n", strings.ToLower(wl.Kind()), wl.Name(), c.Name())
+			fmt.Fprintf(w, "  reverted: %s -> %s\n", wl.ContainerImage(c.Name()), c.Image())
+			fmt.Fprintf(w, "  reason:   %s\n", r)
+		}
+	}
+
+	return nil
+}
+
+// rollbackCronJobs restores the images before deploy to all given CronJobs
+// containers must hold the target containers before deploy, keyed by kubernetes.WorkloadKey
+func rollbackCronJobs(w io.Writer, client *kubernetes.Client, cronJobs []*kubernetes.CronJob, containers map[string][]*kubernetes.Container, user, cause string) error {
+	for _, j := range cronJobs {
+		cs := containers[kubernetes.WorkloadKey(j)]
+
+		if _, err := client.SetCronJobImage(j, containerImages(cs), user, composeAutoRollbackCause(cause)); err != nil {
+			return errors.Wrapf(err, "failed to roll back CronJob %q", j.Name())
+		}
+
+		for _, c := range cs {
+			fmt.Fprintf(w, "rolled back (cronjob: %q, container: %q)\n", j.Name(), c.Name())
+			fmt.Fprintf(w, "  reverted: %s\n", c.Image())
+		}
+	}
+
+	return nil
+}
+
+// containerImages returns the current images of the given containers keyed by container name
+func containerImages(containers []*kubernetes.Container) map[string]string {
+	images := map[string]string{}
+
+	for _, c := range containers {
+		images[c.Name()] = c.Image()
+	}
+
+	return images
+}
+
+func composeAutoRollbackCause(cause string) string {
+	return fmt.Sprintf("k8ship auto-rollback (%s)", cause)
+}
@@ -10,15 +10,17 @@ import (
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
-	SilenceErrors: true,
-	SilenceUsage:  true,
-	Use:           "k8ship",
-	Short:         "Ship image to Kubernetes easily",
+	SilenceErrors:     true,
+	SilenceUsage:      true,
+	Use:               "k8ship",
+	Short:             "Ship image to Kubernetes easily",
+	PersistentPreRunE: initConfig,
 }
 
 var rootOpts = struct {
 	annotationPrefix string
 	context          string
+	env              string
 	kubeconfig       string
 }{}
 
@@ -32,24 +34,57 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-
 	RootCmd.PersistentFlags().StringVar(&rootOpts.annotationPrefix, "annotation-prefix", "", "annotation prefix")
-	RootCmd.PersistentFlags().StringVar(&rootOpts.context, "context", "", "Kubernetes context")
+	RootCmd.PersistentFlags().StringVar(&rootOpts.context, "context", "", "Kubernetes context (deploy, history and reload accept comma-separated contexts)")
+	RootCmd.PersistentFlags().StringVarP(&rootOpts.env, "env", "e", "", "environment defined in config file")
 	RootCmd.PersistentFlags().StringVar(&rootOpts.kubeconfig, "kubeconfig", "", "kubeconfig path")
 }
 
 // initConfig reads in config file and ENV variables if set.
-func initConfig() {
+// The precedence is: flag > ENV variable > config file > default value
+func initConfig(cmd *cobra.Command, args []string) error {
+	if err := loadConfigFiles(); err != nil {
+		return err
+	}
+
 	if rootOpts.annotationPrefix == "" {
 		rootOpts.annotationPrefix = os.Getenv("K8SHIP_ANNOTATION_PREFIX")
 	}
 
+	if rootOpts.annotationPrefix == "" {
+		rootOpts.annotationPrefix = configString("annotation_prefix")
+	}
+
+	if rootOpts.context == "" {
+		rootOpts.context = configString("context")
+	}
+
+	if rootOpts.kubeconfig == "" {
+		rootOpts.kubeconfig = os.Getenv("KUBECONFIG")
+	}
+
 	if rootOpts.kubeconfig == "" {
-		if os.Getenv("KUBECONFIG") == "" {
-			rootOpts.kubeconfig = kubernetes.DefaultConfigFile()
-		} else {
-			rootOpts.kubeconfig = os.Getenv("KUBECONFIG")
+		rootOpts.kubeconfig = configString("kubeconfig")
+	}
+
+	if rootOpts.kubeconfig == "" {
+		rootOpts.kubeconfig = kubernetes.DefaultConfigFile()
+	}
+
+	if err := setFlagFromConfig(cmd, "namespace", "namespace"); err != nil {
+		return err
+	}
+
+	// $USER is the default value of --user, not k8ship-specific ENV variable
+	if err := setFlagFromConfig(cmd, "user", "user"); err != nil {
+		return err
+	}
+
+	if os.Getenv("GITHUB_ACCESS_TOKEN") == "" {
+		if err := setFlagFromConfig(cmd, "access-token", "github.access_token"); err != nil {
+			return err
 		}
 	}
+
+	return nil
 }
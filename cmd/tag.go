@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/dtan4/k8ship/kubernetes"
 	"github.com/pkg/errors"
@@ -18,11 +19,18 @@ var tagCmd = &cobra.Command{
 }
 
 var tagOpts = struct {
-	container  string
-	deployment string
-	dryRun     bool
-	namespace  string
-	user       string
+	accessToken    string
+	container      string
+	deployment     string
+	dryRun         bool
+	kind           string
+	logURL         string
+	namespace      string
+	pinDigest      bool
+	skipImageCheck bool
+	timeout        time.Duration
+	user           string
+	wait           bool
 }{}
 
 func doTag(cmd *cobra.Command, args []string) error {
@@ -31,58 +39,110 @@ func doTag(cmd *cobra.Command, args []string) error {
 	}
 	tag := args[0]
 
-	client, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, rootOpts.context)
+	kubeContext, err := singleKubeContext()
+	if err != nil {
+		return err
+	}
+
+	client, err := kubernetes.NewClient(rootOpts.annotationPrefix, rootOpts.kubeconfig, kubeContext)
 	if err != nil {
 		return errors.Wrap(err, "failed to create Kubernetes client")
 	}
 
-	deployment, err := client.DetectTargetDeployment(tagOpts.namespace, tagOpts.deployment)
+	kind, err := kubernetes.ParseKind(tagOpts.kind)
+	if err != nil {
+		return err
+	}
+
+	workload, err := client.DetectTargetWorkload(tagOpts.namespace, kind, tagOpts.deployment)
 	if err != nil {
-		return errors.Wrap(err, "failed to detect target Deployment")
+		return errors.Wrapf(err, "failed to detect target %s", kind)
 	}
 
-	container, err := client.DetectTargetContainer(deployment, tagOpts.container)
+	container, err := client.DetectTargetContainer(workload, tagOpts.container)
 	if err != nil {
 		return errors.Wrap(err, "failed to detect target container")
 	}
 
-	currentImage := deployment.ContainerImage(container.Name())
-	newImage := strings.Split(currentImage, ":")[0] + ":" + tag
+	currentImage, err := kubernetes.ParseImage(workload.ContainerImage(container.Name()))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse current image")
+	}
+
+	newImage := currentImage.WithTag(tag).String()
+
+	if !tagOpts.skipImageCheck {
+		if err := checkImageExists(client, []kubernetes.Workload{workload}, newImage); err != nil {
+			return err
+		}
+	}
+
+	if tagOpts.pinDigest || workload.PinDigest() {
+		newImage, err = resolveImageDigest(client, []kubernetes.Workload{workload}, newImage)
+		if err != nil {
+			return err
+		}
+	}
 
 	if tagOpts.dryRun {
-		fmt.Printf("[dry-run] deploy to (deployment: %q, container: %q)\n", deployment.Name(), container.Name())
+		fmt.Printf("[dry-run] deploy to (%s: %q, container: %q)\n", strings.ToLower(workload.Kind()), workload.Name(), container.Name())
 		fmt.Printf("[dry-run]  before: %s\n", container.Image())
 		fmt.Printf("[dry-run]   after: %s\n", newImage)
 	} else {
-		fmt.Printf("deploy to (deployment: %q, container: %q)\n", deployment.Name(), container.Name())
+		ghDeployment, err := createGitHubDeploymentForImage(client, tagOpts.accessToken, workload, container.Name(), newImage, tagOpts.user, tagOpts.logURL)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("deploy to (%s: %q, container: %q)\n", strings.ToLower(workload.Kind()), workload.Name(), container.Name())
 		fmt.Printf("  before: %s\n", container.Image())
 		fmt.Printf("   after: %s\n", newImage)
 
-		if _, err := client.SetImage(
-			deployment, container.Name(), newImage, tagOpts.user, composeTagCause(tag, container.Name(), deployment.Name(), tagOpts.namespace),
-		); err != nil {
-			return errors.Wrap(err, "failed to set image")
+		cause := composeTagCause(tag, container.Name(), workload.Kind(), workload.Name(), tagOpts.namespace)
+
+		if _, err := setImageWithGitHubDeployment(client, ghDeployment, workload, container.Name(), newImage, tagOpts.user, cause, tagOpts.wait, tagOpts.timeout); err != nil {
+			return err
 		}
 
-		fmt.Printf("\n")
-		fmt.Printf("deployment successfully updated! check rollout status by `kubectl rollout status deployment/DEPLOYMENT --namespace %s`\n", tagOpts.namespace)
+		if tagOpts.wait {
+			fmt.Printf("\n")
+			fmt.Printf("%s successfully rolled out!\n", strings.ToLower(workload.Kind()))
+		} else {
+			fmt.Printf("%s successfully updated! check rollout status by `kubectl rollout status %s/%s --namespace %s`\n", strings.ToLower(workload.Kind()), strings.ToLower(workload.Kind()), workload.Name(), tagOpts.namespace)
+		}
 	}
 
 	return nil
 }
 
-func composeTagCause(tag, container, deployment, namespace string) string {
-	return fmt.Sprintf(`k8ship tag %s --container "%s" --deployment "%s" --namespace "%s"`, tag, container, deployment, namespace)
+func composeTagCause(tag, container, kind, name, namespace string) string {
+	var kindFlag string
+	if kind != kubernetes.KindDeployment {
+		kindFlag = fmt.Sprintf(` --kind "%s"`, kind)
+	}
+
+	return fmt.Sprintf(`k8ship tag %s --container "%s" --deployment "%s"%s --namespace "%s"`, tag, container, name, kindFlag, namespace)
 }
 
 func init() {
 	RootCmd.AddCommand(tagCmd)
 
+	tagCmd.Flags().StringVar(&tagOpts.accessToken, "access-token", "", "GitHub access token")
 	tagCmd.Flags().StringVarP(&tagOpts.container, "container", "c", "", "target container")
-	tagCmd.Flags().StringVarP(&tagOpts.deployment, "deployment", "d", "", "target Deployment")
+	tagCmd.Flags().StringVarP(&tagOpts.deployment, "deployment", "d", "", "name of target workload (Deployment by default, see --kind)")
 	tagCmd.Flags().BoolVar(&tagOpts.dryRun, "dry-run", false, "dry run")
+	tagCmd.Flags().StringVar(&tagOpts.kind, "kind", kubernetes.KindDeployment, "kind of target workload (Deployment, StatefulSet or DaemonSet)")
+	tagCmd.Flags().StringVar(&tagOpts.logURL, "log-url", "", "URL of deploy log attached to GitHub Deployment status")
 	tagCmd.Flags().StringVarP(&tagOpts.namespace, "namespace", "n", kubernetes.DefaultNamespace(), "Kubernetes namespace")
+	tagCmd.Flags().BoolVar(&tagOpts.pinDigest, "pin-digest", false, "pin the image by digest of its manifest")
+	tagCmd.Flags().BoolVar(&tagOpts.skipImageCheck, "skip-image-check", false, "skip checking that the image exists in registry")
+	tagCmd.Flags().DurationVar(&tagOpts.timeout, "timeout", defaultRolloutTimeout, "timeout of waiting for rollout")
 	tagCmd.Flags().StringVarP(&tagOpts.user, "user", "u", "", "image tag to deploy (default: current login user)")
+	tagCmd.Flags().BoolVar(&tagOpts.wait, "wait", false, "wait for rollout to finish")
+
+	if tagOpts.accessToken == "" {
+		tagOpts.accessToken = os.Getenv("GITHUB_ACCESS_TOKEN")
+	}
 
 	if tagOpts.user == "" {
 		tagOpts.user = os.Getenv("USER")
@@ -2,6 +2,11 @@ package github
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/google/go-github/github"
@@ -9,6 +14,60 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// Deployment states
+// https://developer.github.com/v3/repos/deployments/#create-a-deployment-status
+const (
+	DeploymentStateError      = "error"
+	DeploymentStateFailure    = "failure"
+	DeploymentStateInProgress = "in_progress"
+	DeploymentStatePending    = "pending"
+	DeploymentStateSuccess    = "success"
+)
+
+// Comparison statuses
+// https://developer.github.com/v3/repos/commits/#compare-two-commits
+const (
+	ComparisonStatusAhead     = "ahead"
+	ComparisonStatusBehind    = "behind"
+	ComparisonStatusDiverged  = "diverged"
+	ComparisonStatusIdentical = "identical"
+)
+
+// Commit states
+// https://developer.github.com/v3/repos/statuses/
+const (
+	CommitStateError   = "error"
+	CommitStateFailure = "failure"
+	CommitStatePending = "pending"
+	CommitStateSuccess = "success"
+)
+
+const (
+	comparePerPage       = 100
+	maxDescriptionLength = 140
+
+	commitPullsMediaType = "application/vnd.github.groot-preview+json"
+)
+
+// DeploymentOptions represents the options of Deployment
+type DeploymentOptions struct {
+	AutoInactive          *bool
+	Description           string
+	Environment           string
+	EnvironmentURL        string
+	Payload               map[string]interface{}
+	ProductionEnvironment *bool
+	TransientEnvironment  *bool
+}
+
+// DeploymentStatusOptions represents the options of Deployment status
+type DeploymentStatusOptions struct {
+	AutoInactive   *bool
+	Description    string
+	EnvironmentURL string
+	LogURL         string
+}
+
 // Client represents the wrapper of GitHub API client
 type Client struct {
 	client *github.Client
@@ -29,15 +88,271 @@ func NewClient(ctx context.Context, accessToken string) *Client {
 	}
 }
 
+var (
+	mergeCommitRegexp  = regexp.MustCompile(`^Merge pull request #(\d+) `)
+	squashCommitRegexp = regexp.MustCompile(`\(#(\d+)\)$`)
+)
+
+// Commit represents the commit in comparison
+type Commit struct {
+	Author  string
+	Message string
+	SHA     string
+}
+
+// Comparison represents the comparison of two commits
+// Status is the status of head commit against base commit,
+// and Commits are the commits reachable from head but not from base
+type Comparison struct {
+	AheadBy  int
+	BehindBy int
+	Commits  []*Commit
+	Status   string
+}
+
+// PullRequest represents pull request
+type PullRequest struct {
+	Author string
+	Labels []string
+	Number int
+	Title  string
+	URL    string
+}
+
+// CommitStatus represents the state of status or check run of commit
+type CommitStatus struct {
+	Context string
+	State   string
+}
+
+type commitPull struct {
+	MergedAt *string `json:"merged_at"`
+	Number   int     `json:"number"`
+}
+
+// CommitStatuses returns the statuses and check runs of the given ref
+// The state of check run is converted to the one of commit status
+func (c *Client) CommitStatuses(repo, ref string) ([]*CommitStatus, error) {
+	statuses, err := c.CombinedStatus(repo, ref)
+	if err != nil {
+		return []*CommitStatus{}, err
+	}
+
+	checkRuns, err := c.CheckRuns(repo, ref)
+	if err != nil {
+		return []*CommitStatus{}, err
+	}
+
+	return append(statuses, checkRuns...), nil
+}
+
+// CombinedStatus returns the latest statuses of the given ref
+// https://developer.github.com/v3/repos/statuses/#get-the-combined-status-for-a-specific-ref
+func (c *Client) CombinedStatus(repo, ref string) ([]*CommitStatus, error) {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return []*CommitStatus{}, err
+	}
+
+	statuses := []*CommitStatus{}
+
+	opt := &github.ListOptions{
+		PerPage: 100,
+	}
+
+	for {
+		cs, resp, err := c.client.Repositories.GetCombinedStatus(c.ctx, owner, name, ref, opt)
+		if err != nil {
+			return []*CommitStatus{}, errors.Wrapf(err, "failed to retrieve combined status of %q", ref)
+		}
+
+		for _, s := range cs.Statuses {
+			statuses = append(statuses, &CommitStatus{
+				Context: s.GetContext(),
+				State:   s.GetState(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return statuses, nil
+}
+
+// CheckRuns returns the check runs of the given ref
+// https://developer.github.com/v3/checks/runs/#list-check-runs-for-a-specific-ref
+func (c *Client) CheckRuns(repo, ref string) ([]*CommitStatus, error) {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return []*CommitStatus{}, err
+	}
+
+	statuses := []*CommitStatus{}
+
+	opt := &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		runs, resp, err := c.client.Checks.ListCheckRunsForRef(c.ctx, owner, name, ref, opt)
+		if err != nil {
+			return []*CommitStatus{}, errors.Wrapf(err, "failed to retrieve check runs of %q", ref)
+		}
+
+		for _, r := range runs.CheckRuns {
+			statuses = append(statuses, &CommitStatus{
+				Context: r.GetName(),
+				State:   checkRunState(r),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return statuses, nil
+}
+
+// CommitPullRequestNumber returns the number of pull request which merged the given commit
+// Pull requests merged by rebase, whose commit message has no reference to the pull request, are also found
+// 0 is returned if the commit was not merged by pull request
+// https://developer.github.com/v3/repos/commits/#list-pull-requests-associated-with-commit
+func (c *Client) CommitPullRequestNumber(repo, sha1 string) (int, error) {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := c.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/commits/%s/pulls", owner, name, sha1), nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create request")
+	}
+
+	req.Header.Set("Accept", commitPullsMediaType)
+
+	var pulls []*commitPull
+
+	if _, err := c.client.Do(c.ctx, req, &pulls); err != nil {
+		return 0, errors.Wrapf(err, "failed to retrieve pull requests associated with %s", sha1)
+	}
+
+	for _, p := range pulls {
+		if p.MergedAt != nil {
+			return p.Number, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// CompareCommits compares head commit with base commit
+// Commits are requested page by page, because the compare API returns up to 250 commits at once
+// go-github does not support pagination of the compare API, so the request is composed here
+// https://developer.github.com/v3/repos/commits/#compare-two-commits
+func (c *Client) CompareCommits(repo, base, head string) (*Comparison, error) {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var cmp *github.CommitsComparison
+
+	commits := []*Commit{}
+	page := 1
+
+	for {
+		req, err := c.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/compare/%s...%s?per_page=%d&page=%d", owner, name, base, head, comparePerPage, page), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create request")
+		}
+
+		cmp = &github.CommitsComparison{}
+
+		resp, err := c.client.Do(c.ctx, req, cmp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compare %s...%s", base, head)
+		}
+
+		for _, rc := range cmp.Commits {
+			author := rc.GetAuthor().GetLogin()
+			if author == "" {
+				author = rc.GetCommit().GetAuthor().GetName()
+			}
+
+			commits = append(commits, &Commit{
+				Author:  author,
+				Message: rc.GetCommit().GetMessage(),
+				SHA:     rc.GetSHA(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		page = resp.NextPage
+	}
+
+	return &Comparison{
+		AheadBy:  cmp.GetAheadBy(),
+		BehindBy: cmp.GetBehindBy(),
+		Commits:  commits,
+		Status:   cmp.GetStatus(),
+	}, nil
+}
+
+// PullRequestNumber returns the number of pull request merged by this commit
+// Merge commit ("Merge pull request #123 from ...") and squashed commit ("Subject (#123)") are supported
+// 0 is returned if the commit does not merge pull request. Use Client.CommitPullRequestNumber for rebased commit
+func (c *Commit) PullRequestNumber() int {
+	subject := c.Subject()
+
+	for _, re := range []*regexp.Regexp{mergeCommitRegexp, squashCommitRegexp} {
+		if m := re.FindStringSubmatch(subject); m != nil {
+			n, err := strconv.Atoi(m[1])
+			if err != nil {
+				return 0
+			}
+
+			return n
+		}
+	}
+
+	return 0
+}
+
+// ShortSHA returns the first 7 characters of commit SHA-1
+func (c *Commit) ShortSHA() string {
+	if len(c.SHA) < 7 {
+		return c.SHA
+	}
+
+	return c.SHA[0:7]
+}
+
+// Subject returns the first line of commit message
+func (c *Commit) Subject() string {
+	return strings.SplitN(c.Message, "\n", 2)[0]
+}
+
 // CommitFronRef returns the latest commit SHA-1 of the given ref
 // (branch, full commit SHA-1, short commit SHA-1...)
 func (c *Client) CommitFronRef(repo, ref string) (string, error) {
-	ss := strings.Split(repo, "/")
-	if len(ss) != 2 {
-		return "", errors.Errorf("invalid repository %q, must be owner/repo", repo)
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return "", err
 	}
 
-	sha1, _, err := c.client.Repositories.GetCommitSHA1(c.ctx, ss[0], ss[1], ref, "")
+	sha1, _, err := c.client.Repositories.GetCommitSHA1(c.ctx, owner, name, ref, "")
 	if err != nil {
 		return "", errors.Wrap(err, "failed to retrieve commit SHA-1")
 	}
@@ -45,30 +360,278 @@ func (c *Client) CommitFronRef(repo, ref string) (string, error) {
 	return sha1, nil
 }
 
-// CreateDeployment creates Deployment and returns Deployment ID
+// CreateDeployment creates Deployment with "pending" status and returns Deployment ID
+// description longer than 140 characters is truncated
 // https://developer.github.com/v3/repos/deployments/
-func (c *Client) CreateDeployment(repo, ref, cluster string) (int, error) {
-	ss := strings.Split(repo, "/")
-	if len(ss) != 2 {
-		return -1, errors.Errorf("invalid repository %q, must be owner/repo", repo)
+func (c *Client) CreateDeployment(repo, ref string, opts *DeploymentOptions) (int64, error) {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return -1, err
 	}
 
-	d, _, err := c.client.Repositories.CreateDeployment(c.ctx, ss[0], ss[1], &github.DeploymentRequest{
-		Description:      github.String("k8ship deploy"),
-		Environment:      github.String(cluster),
-		Ref:              github.String(ref),
-		RequiredContexts: &[]string{},
-	})
+	req := &github.DeploymentRequest{
+		Environment:           github.String(opts.Environment),
+		ProductionEnvironment: opts.ProductionEnvironment,
+		Ref:                   github.String(ref),
+		RequiredContexts:      &[]string{},
+		TransientEnvironment:  opts.TransientEnvironment,
+	}
+
+	if opts.Description != "" {
+		req.Description = github.String(truncateDescription(opts.Description))
+	}
+
+	if len(opts.Payload) > 0 {
+		payload, err := json.Marshal(opts.Payload)
+		if err != nil {
+			return -1, errors.Wrap(err, "failed to encode Deployment payload")
+		}
+
+		req.Payload = github.String(string(payload))
+	}
+
+	d, _, err := c.client.Repositories.CreateDeployment(c.ctx, owner, name, req)
 	if err != nil {
 		return -1, errors.Wrap(err, "failed to create Deployment")
 	}
 
-	_, _, err = c.client.Repositories.CreateDeploymentStatus(c.ctx, ss[0], ss[1], d.GetID(), &github.DeploymentStatusRequest{
-		State: github.String("success"),
+	if err := c.UpdateDeploymentStatus(repo, d.GetID(), DeploymentStatePending, &DeploymentStatusOptions{
+		AutoInactive:   opts.AutoInactive,
+		EnvironmentURL: opts.EnvironmentURL,
+	}); err != nil {
+		return -1, err
+	}
+
+	return d.GetID(), nil
+}
+
+// CreateOrUpdateComment updates the comment of issue (or pull request) containing marker,
+// or creates new comment if no comment contains marker
+// https://developer.github.com/v3/issues/comments/
+func (c *Client) CreateOrUpdateComment(repo string, number int, marker, body string) error {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return err
+	}
+
+	opt := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		comments, resp, err := c.client.Issues.ListComments(c.ctx, owner, name, number, opt)
+		if err != nil {
+			return errors.Wrapf(err, "failed to retrieve comments of #%d", number)
+		}
+
+		for _, comment := range comments {
+			if !strings.Contains(comment.GetBody(), marker) {
+				continue
+			}
+
+			if _, _, err := c.client.Issues.EditComment(c.ctx, owner, name, comment.GetID(), &github.IssueComment{
+				Body: github.String(body),
+			}); err != nil {
+				return errors.Wrapf(err, "failed to update comment %d of #%d", comment.GetID(), number)
+			}
+
+			return nil
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	if _, _, err := c.client.Issues.CreateComment(c.ctx, owner, name, number, &github.IssueComment{
+		Body: github.String(body),
+	}); err != nil {
+		return errors.Wrapf(err, "failed to create comment on #%d", number)
+	}
+
+	return nil
+}
+
+// CreateRelease creates GitHub Release with the given tag at the target commit and returns its URL
+// The tag is created if it does not exist
+// https://developer.github.com/v3/repos/releases/#create-a-release
+func (c *Client) CreateRelease(repo, tag, target, title, body string) (string, error) {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return "", err
+	}
+
+	r, _, err := c.client.Repositories.CreateRelease(c.ctx, owner, name, &github.RepositoryRelease{
+		Body:            github.String(body),
+		Name:            github.String(title),
+		TagName:         github.String(tag),
+		TargetCommitish: github.String(target),
 	})
 	if err != nil {
-		return -1, errors.Wrap(err, "failed to update Deployment status: 'pending' -> 'success'")
+		return "", errors.Wrapf(err, "failed to create Release %q", tag)
 	}
 
-	return d.GetID(), nil
+	return r.GetHTMLURL(), nil
+}
+
+// CreateTag creates tag at the given commit
+// Annotated tag is created if message is not empty, otherwise lightweight tag is created
+// https://developer.github.com/v3/git/tags/
+func (c *Client) CreateTag(repo, tag, sha1, message string) error {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return err
+	}
+
+	target := sha1
+
+	if message != "" {
+		t, _, err := c.client.Git.CreateTag(c.ctx, owner, name, &github.Tag{
+			Message: github.String(message),
+			Object: &github.GitObject{
+				SHA:  github.String(sha1),
+				Type: github.String("commit"),
+			},
+			Tag: github.String(tag),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create tag object %q", tag)
+		}
+
+		target = t.GetSHA()
+	}
+
+	if _, _, err := c.client.Git.CreateRef(c.ctx, owner, name, &github.Reference{
+		Object: &github.GitObject{
+			SHA: github.String(target),
+		},
+		Ref: github.String("refs/tags/" + tag),
+	}); err != nil {
+		return errors.Wrapf(err, "failed to create tag %q", tag)
+	}
+
+	return nil
+}
+
+// PullRequest returns the pull request of the given number
+// Issues API is used to retrieve labels together
+// https://developer.github.com/v3/issues/#get-a-single-issue
+func (c *Client) PullRequest(repo string, number int) (*PullRequest, error) {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	issue, _, err := c.client.Issues.Get(c.ctx, owner, name, number)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve pull request #%d", number)
+	}
+
+	labels := []string{}
+
+	for _, l := range issue.Labels {
+		labels = append(labels, l.GetName())
+	}
+
+	return &PullRequest{
+		Author: issue.GetUser().GetLogin(),
+		Labels: labels,
+		Number: number,
+		Title:  issue.GetTitle(),
+		URL:    issue.GetHTMLURL(),
+	}, nil
+}
+
+// TagExists returns whether the tag exists in the repository
+func (c *Client) TagExists(repo, tag string) (bool, error) {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return false, err
+	}
+
+	ref, resp, err := c.client.Git.GetRef(c.ctx, owner, name, "tags/"+tag)
+	if err != nil {
+		// GitHub returns the list of refs which start with the given name if there is no exact match
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusOK) {
+			return false, nil
+		}
+
+		return false, errors.Wrapf(err, "failed to retrieve tag %q", tag)
+	}
+
+	return ref.GetRef() == "refs/tags/"+tag, nil
+}
+
+// UpdateDeploymentStatus creates new status of the given Deployment
+// description longer than 140 characters is truncated
+func (c *Client) UpdateDeploymentStatus(repo string, id int64, state string, opts *DeploymentStatusOptions) error {
+	owner, name, err := splitRepository(repo)
+	if err != nil {
+		return err
+	}
+
+	req := &github.DeploymentStatusRequest{
+		AutoInactive: opts.AutoInactive,
+		State:        github.String(state),
+	}
+
+	if opts.Description != "" {
+		req.Description = github.String(truncateDescription(opts.Description))
+	}
+
+	if opts.EnvironmentURL != "" {
+		req.EnvironmentURL = github.String(opts.EnvironmentURL)
+	}
+
+	if opts.LogURL != "" {
+		req.LogURL = github.String(opts.LogURL)
+	}
+
+	if _, _, err := c.client.Repositories.CreateDeploymentStatus(c.ctx, owner, name, id, req); err != nil {
+		return errors.Wrapf(err, "failed to update Deployment status to %q", state)
+	}
+
+	return nil
+}
+
+// checkRunState converts the status and conclusion of check run to commit state
+// https://developer.github.com/v3/checks/runs/#parameters
+func checkRunState(r *github.CheckRun) string {
+	if r.GetStatus() != "completed" {
+		return CommitStatePending
+	}
+
+	switch r.GetConclusion() {
+	case "success", "neutral", "skipped":
+		return CommitStateSuccess
+	case "cancelled", "timed_out", "action_required":
+		return CommitStateError
+	default:
+		return CommitStateFailure
+	}
+}
+
+func splitRepository(repo string) (string, string, error) {
+	ss := strings.Split(repo, "/")
+	if len(ss) != 2 {
+		return "", "", errors.Errorf("invalid repository %q, must be owner/repo", repo)
+	}
+
+	return ss[0], ss[1], nil
+}
+
+// truncateDescription truncates description longer than 140 characters
+// Description is cut by runes, so that multibyte characters are not broken
+func truncateDescription(description string) string {
+	rs := []rune(description)
+
+	if len(rs) > maxDescriptionLength {
+		return string(rs[0:maxDescriptionLength-3]) + "..."
+	}
+
+	return description
 }
@@ -0,0 +1,236 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) (*Client, func()) {
+	server := httptest.NewServer(handler)
+
+	client := github.NewClient(nil)
+
+	u, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = u
+
+	return &Client{
+		client: client,
+		ctx:    context.Background(),
+	}, server.Close
+}
+
+func TestCheckRunState(t *testing.T) {
+	testcases := []struct {
+		status     string
+		conclusion string
+		expected   string
+	}{
+		{
+			status:   "queued",
+			expected: CommitStatePending,
+		},
+		{
+			status:   "in_progress",
+			expected: CommitStatePending,
+		},
+		{
+			status:     "completed",
+			conclusion: "success",
+			expected:   CommitStateSuccess,
+		},
+		{
+			status:     "completed",
+			conclusion: "neutral",
+			expected:   CommitStateSuccess,
+		},
+		{
+			status:     "completed",
+			conclusion: "skipped",
+			expected:   CommitStateSuccess,
+		},
+		{
+			status:     "completed",
+			conclusion: "cancelled",
+			expected:   CommitStateError,
+		},
+		{
+			status:     "completed",
+			conclusion: "timed_out",
+			expected:   CommitStateError,
+		},
+		{
+			status:     "completed",
+			conclusion: "action_required",
+			expected:   CommitStateError,
+		},
+		{
+			status:     "completed",
+			conclusion: "failure",
+			expected:   CommitStateFailure,
+		},
+	}
+
+	for _, tc := range testcases {
+		r := &github.CheckRun{
+			Status: github.String(tc.status),
+		}
+		if tc.conclusion != "" {
+			r.Conclusion = github.String(tc.conclusion)
+		}
+
+		got := checkRunState(r)
+		if got != tc.expected {
+			t.Errorf("status: %q, conclusion: %q, expected: %q, got: %q", tc.status, tc.conclusion, tc.expected, got)
+		}
+	}
+}
+
+func TestCompareCommits(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/dtan4/k8ship/compare/abc...def", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/dtan4/k8ship/compare/abc...def?per_page=100&page=2>; rel="next"`, r.Host))
+			fmt.Fprint(w, `{"status":"ahead","ahead_by":2,"behind_by":0,"commits":[{"sha":"1111111111","author":{"login":"dtan4"},"commit":{"message":"Merge pull request #1 from dtan4/foo\n\nFoo"}}]}`)
+		case "2":
+			fmt.Fprint(w, `{"status":"ahead","ahead_by":2,"behind_by":0,"commits":[{"sha":"2222222222","commit":{"author":{"name":"Daisuke Fujita"},"message":"Bar (#2)"}}]}`)
+		default:
+			t.Errorf("unexpected page: %q", r.URL.Query().Get("page"))
+		}
+	})
+
+	client, teardown := newTestClient(t, mux)
+	defer teardown()
+
+	got, err := client.CompareCommits("dtan4/k8ship", "abc", "def")
+	if err != nil {
+		t.Errorf("got error: %s", err)
+		return
+	}
+
+	if got.Status != ComparisonStatusAhead {
+		t.Errorf("expected status: %q, got: %q", ComparisonStatusAhead, got.Status)
+	}
+
+	if got.AheadBy != 2 {
+		t.Errorf("expected ahead by: 2, got: %d", got.AheadBy)
+	}
+
+	expected := []*Commit{
+		&Commit{
+			Author:  "dtan4",
+			Message: "Merge pull request #1 from dtan4/foo\n\nFoo",
+			SHA:     "1111111111",
+		},
+		&Commit{
+			Author:  "Daisuke Fujita",
+			Message: "Bar (#2)",
+			SHA:     "2222222222",
+		},
+	}
+
+	if len(got.Commits) != len(expected) {
+		t.Errorf("expected length: %d, got: %d", len(expected), len(got.Commits))
+		return
+	}
+
+	for i, c := range got.Commits {
+		if *c != *expected[i] {
+			t.Errorf("expected: %#v, got: %#v", expected[i], c)
+		}
+	}
+}
+
+func TestCommitPullRequestNumber(t *testing.T) {
+	testcases := []struct {
+		message  string
+		expected int
+	}{
+		{
+			message:  "Merge pull request #123 from dtan4/foo\n\nAdd foo",
+			expected: 123,
+		},
+		{
+			message:  "Add foo (#45)",
+			expected: 45,
+		},
+		{
+			message:  "Add foo (#45)\n\n* Add bar",
+			expected: 45,
+		},
+		{
+			message:  "Add foo",
+			expected: 0,
+		},
+		{
+			message:  "Revert \"Add foo (#45)\" for #46",
+			expected: 0,
+		},
+		{
+			message:  "Merge branch 'master' into foo",
+			expected: 0,
+		},
+		{
+			message:  "",
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := &Commit{
+			Message: tc.message,
+		}
+
+		got := c.PullRequestNumber()
+		if got != tc.expected {
+			t.Errorf("message: %q, expected: %d, got: %d", tc.message, tc.expected, got)
+		}
+	}
+}
+
+func TestTruncateDescription(t *testing.T) {
+	testcases := []struct {
+		description string
+		expected    string
+	}{
+		{
+			description: "k8ship deploy master by dtan4",
+			expected:    "k8ship deploy master by dtan4",
+		},
+		{
+			description: strings.Repeat("a", 140),
+			expected:    strings.Repeat("a", 140),
+		},
+		{
+			description: strings.Repeat("a", 141),
+			expected:    strings.Repeat("a", 137) + "...",
+		},
+		{
+			description: strings.Repeat("あ", 140),
+			expected:    strings.Repeat("あ", 140),
+		},
+		{
+			description: strings.Repeat("あ", 141),
+			expected:    strings.Repeat("あ", 137) + "...",
+		},
+	}
+
+	for _, tc := range testcases {
+		got := truncateDescription(tc.description)
+		if got != tc.expected {
+			t.Errorf("expected: %q, got: %q", tc.expected, got)
+		}
+	}
+}
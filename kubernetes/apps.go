@@ -0,0 +1,189 @@
+package kubernetes
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	appsV1GroupVersion = "apps/v1"
+)
+
+// detectLegacyAppsAPI returns whether the cluster does not serve apps/v1
+// Deployments and ReplicaSets are served in extensions/v1beta1 on such clusters (Kubernetes 1.8 or below)
+func detectLegacyAppsAPI(clientset kubernetes.Interface) (bool, error) {
+	if _, err := clientset.Discovery().ServerResourcesForGroupVersion(appsV1GroupVersion); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, errors.Wrapf(err, "failed to discover API group %q", appsV1GroupVersion)
+	}
+
+	return false, nil
+}
+
+// convertObject converts the object of extensions/v1beta1 to the one of apps/v1 via JSON
+// Deployment and ReplicaSet have the compatible JSON schema in both API groups
+func convertObject(src, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode object")
+	}
+
+	if err := json.Unmarshal(b, dst); err != nil {
+		return errors.Wrap(err, "failed to decode object")
+	}
+
+	return nil
+}
+
+func (c *Client) getDeployment(namespace, name string) (*appsv1.Deployment, error) {
+	if !c.legacyAppsAPI {
+		return c.clientset.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+	}
+
+	d, err := c.clientset.ExtensionsV1beta1().Deployments(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var deployment appsv1.Deployment
+
+	if err := convertObject(d, &deployment); err != nil {
+		return nil, err
+	}
+
+	return &deployment, nil
+}
+
+func (c *Client) listDeployments(namespace, selector string) ([]appsv1.Deployment, error) {
+	if !c.legacyAppsAPI {
+		ds, err := c.clientset.AppsV1().Deployments(namespace).List(metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, err
+		}
+
+		return ds.Items, nil
+	}
+
+	ds, err := c.clientset.ExtensionsV1beta1().Deployments(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var deployments []appsv1.Deployment
+
+	if err := convertObject(ds.Items, &deployments); err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
+
+func (c *Client) listReplicaSets(namespace string) ([]appsv1.ReplicaSet, error) {
+	if !c.legacyAppsAPI {
+		rs, err := c.clientset.AppsV1().ReplicaSets(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		return rs.Items, nil
+	}
+
+	rs, err := c.clientset.ExtensionsV1beta1().ReplicaSets(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var replicaSets []appsv1.ReplicaSet
+
+	if err := convertObject(rs.Items, &replicaSets); err != nil {
+		return nil, err
+	}
+
+	return replicaSets, nil
+}
+
+func (c *Client) patchDeployment(namespace, name string, patch []byte) (*appsv1.Deployment, error) {
+	if !c.legacyAppsAPI {
+		return c.clientset.AppsV1().Deployments(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	}
+
+	d, err := c.clientset.ExtensionsV1beta1().Deployments(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	var deployment appsv1.Deployment
+
+	if err := convertObject(d, &deployment); err != nil {
+		return nil, err
+	}
+
+	return &deployment, nil
+}
+
+// getWorkload retrieves the latest state of the Workload of the given kind and name
+// StatefulSets and DaemonSets are served only in apps/v1
+func (c *Client) getWorkload(namespace, kind, name string) (Workload, error) {
+	switch kind {
+	case KindDeployment:
+		d, err := c.getDeployment(namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDeployment(c.annotationPrefix, d), nil
+	case KindStatefulSet:
+		s, err := c.clientset.AppsV1().StatefulSets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		return NewStatefulSet(c.annotationPrefix, s), nil
+	case KindDaemonSet:
+		d, err := c.clientset.AppsV1().DaemonSets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDaemonSet(c.annotationPrefix, d), nil
+	}
+
+	return nil, errors.Errorf("unsupported kind %q", kind)
+}
+
+func (c *Client) patchWorkload(workload Workload, patch []byte) (Workload, error) {
+	switch workload.Kind() {
+	case KindDeployment:
+		d, err := c.patchDeployment(workload.Namespace(), workload.Name(), patch)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDeployment(c.annotationPrefix, d), nil
+	case KindStatefulSet:
+		s, err := c.clientset.AppsV1().StatefulSets(workload.Namespace()).Patch(workload.Name(), types.StrategicMergePatchType, patch)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewStatefulSet(c.annotationPrefix, s), nil
+	case KindDaemonSet:
+		d, err := c.clientset.AppsV1().DaemonSets(workload.Namespace()).Patch(workload.Name(), types.StrategicMergePatchType, patch)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDaemonSet(c.annotationPrefix, d), nil
+	}
+
+	return nil, errors.Errorf("unsupported kind %q", workload.Kind())
+}
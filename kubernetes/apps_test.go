@@ -0,0 +1,93 @@
+package kubernetes
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDeployment_legacyAppsAPI(t *testing.T) {
+	deployment := &v1beta1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+		},
+		Spec: v1beta1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						v1.Container{
+							Name:  "rails",
+							Image: "my-rails:v3",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(deployment)
+	client := &Client{
+		clientset:     clientset,
+		legacyAppsAPI: true,
+	}
+
+	got, err := client.GetDeployment("default", "deployment")
+	if err != nil {
+		t.Errorf("got error: %s", err)
+		return
+	}
+
+	expected := "my-rails:v3"
+	if image := got.ContainerImage("rails"); image != expected {
+		t.Errorf("expected: %q, got: %q", expected, image)
+	}
+}
+
+func TestListReplicaSets_legacyAppsAPI(t *testing.T) {
+	replicasets := []v1beta1.ReplicaSet{
+		v1beta1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "deployment-1234567890",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					metav1.OwnerReference{
+						UID: (types.UID)("0001"),
+					},
+				},
+			},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(&v1beta1.ReplicaSetList{
+		Items: replicasets,
+	})
+	client := &Client{
+		clientset:     clientset,
+		legacyAppsAPI: true,
+	}
+
+	deployment := NewDeployment("", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+			UID:       (types.UID)("0001"),
+		},
+	})
+
+	got, err := client.ListReplicaSets(deployment)
+	if err != nil {
+		t.Errorf("got error: %s", err)
+		return
+	}
+
+	expectedLength := 1
+	if len(got) != expectedLength {
+		t.Errorf("expected length: %d, got: %d", expectedLength, len(got))
+	}
+}
@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	batchV1GroupVersion = "batch/v1"
+	cronJobsResource    = "cronjobs"
+)
+
+// listCronJobs lists CronJobs in batch/v1 if served, otherwise in batch/v1beta1
+// client-go does not have the typed client of batch/v1 CronJob,
+// so it is requested via REST client and decoded into batch/v1beta1 object which has the compatible JSON schema
+func (c *Client) listCronJobs(namespace, selector string) ([]batchv1beta1.CronJob, error) {
+	if !c.cronJobsV1 {
+		cjs, err := c.clientset.BatchV1beta1().CronJobs(namespace).List(metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, err
+		}
+
+		return cjs.Items, nil
+	}
+
+	req := c.batchV1.Get().Namespace(namespace).Resource(cronJobsResource)
+	if selector != "" {
+		req = req.Param("labelSelector", selector)
+	}
+
+	b, err := req.DoRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	var cronJobs batchv1beta1.CronJobList
+
+	if err := json.Unmarshal(b, &cronJobs); err != nil {
+		return nil, errors.Wrap(err, "failed to decode CronJobs")
+	}
+
+	return cronJobs.Items, nil
+}
+
+func (c *Client) patchCronJob(namespace, name string, patch []byte) (*batchv1beta1.CronJob, error) {
+	if !c.cronJobsV1 {
+		return c.clientset.BatchV1beta1().CronJobs(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	}
+
+	b, err := c.batchV1.Patch(types.StrategicMergePatchType).Namespace(namespace).Resource(cronJobsResource).Name(name).Body(patch).DoRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	var cronJob batchv1beta1.CronJob
+
+	if err := json.Unmarshal(b, &cronJob); err != nil {
+		return nil, errors.Wrap(err, "failed to decode CronJob")
+	}
+
+	return &cronJob, nil
+}
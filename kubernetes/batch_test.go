@@ -0,0 +1,113 @@
+package kubernetes
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakerest "k8s.io/client-go/rest/fake"
+)
+
+func newFakeRESTClient(gv schema.GroupVersion, handler func(*http.Request) (*http.Response, error)) *fakerest.RESTClient {
+	return &fakerest.RESTClient{
+		Client:               fakerest.CreateHTTPClient(handler),
+		GroupVersion:         gv,
+		NegotiatedSerializer: scheme.Codecs,
+		VersionedAPIPath:     "/apis/" + gv.String(),
+	}
+}
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+	}
+}
+
+func TestListCronJobs_batchV1(t *testing.T) {
+	client := &Client{
+		batchV1: newFakeRESTClient(schema.GroupVersion{Group: "batch", Version: "v1"}, func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet || req.URL.Path != "/apis/batch/v1/namespaces/default/cronjobs" {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(&bytes.Buffer{})}, nil
+			}
+
+			if got := req.URL.Query().Get("labelSelector"); got != "role=batch" {
+				t.Errorf("expected labelSelector: %q, got: %q", "role=batch", got)
+			}
+
+			return jsonResponse(`{"apiVersion":"batch/v1","kind":"CronJobList","items":[{"metadata":{"name":"batch","namespace":"default"}}]}`), nil
+		}),
+		clientset:  fake.NewSimpleClientset(),
+		cronJobsV1: true,
+	}
+
+	got, err := client.ListCronJobs("default", "role=batch")
+	if err != nil {
+		t.Errorf("got error: %s", err)
+		return
+	}
+
+	if len(got) != 1 {
+		t.Errorf("expected length: 1, got: %d", len(got))
+		return
+	}
+
+	if got[0].Name() != "batch" {
+		t.Errorf("expected: %q, got: %q", "batch", got[0].Name())
+	}
+}
+
+func TestSetCronJobImage_batchV1(t *testing.T) {
+	client := &Client{
+		batchV1: newFakeRESTClient(schema.GroupVersion{Group: "batch", Version: "v1"}, func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodPatch || req.URL.Path != "/apis/batch/v1/namespaces/default/cronjobs/batch" {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(&bytes.Buffer{})}, nil
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+
+			if !strings.Contains(string(b), `"image":"my-batch:v4"`) {
+				t.Errorf("patch %q does not contain the new image", string(b))
+			}
+
+			return jsonResponse(`{"apiVersion":"batch/v1","kind":"CronJob","metadata":{"name":"batch","namespace":"default"},"spec":{"jobTemplate":{"spec":{"template":{"spec":{"containers":[{"name":"batch","image":"my-batch:v4"}]}}}}}}`), nil
+		}),
+		clientset:  fake.NewSimpleClientset(),
+		cronJobsV1: true,
+	}
+
+	cronJob := NewCronJob("", &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "batch",
+			Namespace: "default",
+		},
+	})
+	cronJob.raw.Spec.JobTemplate.Spec.Template.Spec.Containers = []v1.Container{
+		v1.Container{
+			Name:  "batch",
+			Image: "my-batch:v3",
+		},
+	}
+
+	got, err := client.SetCronJobImage(cronJob, map[string]string{"batch": "my-batch:v4"}, "dtan4", "cause")
+	if err != nil {
+		t.Errorf("got error: %s", err)
+		return
+	}
+
+	if image := got.ContainerImage("batch"); image != "my-batch:v4" {
+		t.Errorf("expected: %q, got: %q", "my-batch:v4", image)
+	}
+}
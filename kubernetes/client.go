@@ -1,21 +1,36 @@
 package kubernetes
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/pkg/api"
-	"k8s.io/client-go/pkg/api/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// ErrCronJobsNotServed is returned by ListCronJobs if the cluster serves CronJobs in neither batch/v1 nor batch/v1beta1
+// (Kubernetes 1.7 or below)
+var ErrCronJobsNotServed = errors.New("CronJobs are served in neither batch/v1 nor batch/v1beta1")
+
 // Client represents the wrapper of Kubernetes API client
 type Client struct {
 	annotationPrefix string
+	batchV1          rest.Interface
 	clientConfig     clientcmd.ClientConfig
 	clientset        kubernetes.Interface
+	context          string
+	cronJobsV1       bool
+	ingressesV1      bool
+	legacyAppsAPI    bool
+	networkingV1     rest.Interface
 }
 
 // NewClient creates Client object using local kubecfg
@@ -34,10 +49,31 @@ func NewClient(annotationPrefix string, kubeconfig, context string) (*Client, er
 		return nil, errors.Wrap(err, "failed to load clientset")
 	}
 
+	legacyAppsAPI, err := detectLegacyAppsAPI(clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobsV1, err := detectResource(clientset, batchV1GroupVersion, cronJobsResource)
+	if err != nil {
+		return nil, err
+	}
+
+	ingressesV1, err := detectResource(clientset, networkingV1GroupVersion, ingressesResource)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Client{
 		annotationPrefix: annotationPrefix,
+		batchV1:          clientset.BatchV1().RESTClient(),
 		clientConfig:     clientConfig,
 		clientset:        clientset,
+		context:          context,
+		cronJobsV1:       cronJobsV1,
+		ingressesV1:      ingressesV1,
+		legacyAppsAPI:    legacyAppsAPI,
+		networkingV1:     clientset.NetworkingV1().RESTClient(),
 	}, nil
 }
 
@@ -53,13 +89,38 @@ func NewClientInCluster(annotationPrefix string) (*Client, error) {
 		return nil, errors.Wrap(err, "falied to load clientset")
 	}
 
+	legacyAppsAPI, err := detectLegacyAppsAPI(clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobsV1, err := detectResource(clientset, batchV1GroupVersion, cronJobsResource)
+	if err != nil {
+		return nil, err
+	}
+
+	ingressesV1, err := detectResource(clientset, networkingV1GroupVersion, ingressesResource)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Client{
-		clientset: clientset,
+		batchV1:       clientset.BatchV1().RESTClient(),
+		clientset:     clientset,
+		cronJobsV1:    cronJobsV1,
+		ingressesV1:   ingressesV1,
+		legacyAppsAPI: legacyAppsAPI,
+		networkingV1:  clientset.NetworkingV1().RESTClient(),
 	}, nil
 }
 
 // CurrentContext returns the current cluster name
+// The context given to NewClient takes precedence over current-context in kubeconfig
 func (c *Client) CurrentContext() (string, error) {
+	if c.context != "" {
+		return c.context, nil
+	}
+
 	rc, err := c.clientConfig.RawConfig()
 	if err != nil {
 		return "", errors.Wrap(err, "failed to retrieve raw kubeconfig")
@@ -69,22 +130,22 @@ func (c *Client) CurrentContext() (string, error) {
 }
 
 // DetectTargetContainer returns the matched or the first container
-func (c *Client) DetectTargetContainer(deployment *Deployment, name string) (*Container, error) {
+func (c *Client) DetectTargetContainer(workload Workload, name string) (*Container, error) {
 	if name == "" {
-		if len(deployment.Containers()) > 1 {
+		if len(workload.Containers()) > 1 {
 			names := []string{}
 
-			for _, c := range deployment.Containers() {
+			for _, c := range workload.Containers() {
 				names = append(names, c.Name())
 			}
 
-			return nil, errors.Errorf("multiple containers %q found in deployment %q", names, deployment.Name())
+			return nil, errors.Errorf("multiple containers %q found in %s %q", names, strings.ToLower(workload.Kind()), workload.Name())
 		}
 
-		return deployment.Containers()[0], nil
+		return workload.Containers()[0], nil
 	}
 
-	for _, c := range deployment.Containers() {
+	for _, c := range workload.Containers() {
 		if c.Name() == name {
 			return c, nil
 		}
@@ -98,7 +159,7 @@ func (c *Client) DetectTargetDeployment(namespace, name string) (*Deployment, er
 	var deployment *Deployment
 
 	if name == "" {
-		ds, err := c.ListDeployments(namespace)
+		ds, err := c.ListDeployments(namespace, "")
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to retrieve Deployments")
 		}
@@ -130,9 +191,121 @@ func (c *Client) DetectTargetDeployment(namespace, name string) (*Deployment, er
 	return deployment, nil
 }
 
+// DetectTargetWorkload returns the matched or the only workload of the given kind
+func (c *Client) DetectTargetWorkload(namespace, kind, name string) (Workload, error) {
+	if name != "" {
+		return c.GetWorkload(namespace, kind, name)
+	}
+
+	if kind == KindDeployment {
+		return c.DetectTargetDeployment(namespace, "")
+	}
+
+	all, err := c.ListWorkloads(namespace, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve workloads")
+	}
+
+	workloads := []Workload{}
+	names := []string{}
+
+	for _, w := range all {
+		if w.Kind() == kind {
+			workloads = append(workloads, w)
+			names = append(names, w.Name())
+		}
+	}
+
+	if len(workloads) == 0 {
+		return nil, errors.Errorf("no %s found in namespace %q", kind, namespace)
+	}
+
+	if len(workloads) > 1 {
+		return nil, errors.Errorf("multiple %ss %q found in namespace %q", kind, names, namespace)
+	}
+
+	return workloads[0], nil
+}
+
+// DockerConfigs returns the Docker configs stored in imagePullSecrets of the given workload
+func (c *Client) DockerConfigs(workload Workload) ([][]byte, error) {
+	configs := [][]byte{}
+
+	for _, name := range workload.ImagePullSecrets() {
+		secret, err := c.clientset.CoreV1().Secrets(workload.Namespace()).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return [][]byte{}, errors.Wrapf(err, "failed to retrieve Secret %q", name)
+		}
+
+		switch secret.Type {
+		case v1.SecretTypeDockerConfigJson:
+			configs = append(configs, secret.Data[v1.DockerConfigJsonKey])
+		case v1.SecretTypeDockercfg:
+			configs = append(configs, secret.Data[v1.DockerConfigKey])
+		}
+	}
+
+	return configs, nil
+}
+
+// EnvironmentURL returns the URL of Ingress host which routes to the given workload
+// Ingresses are read from networking.k8s.io/v1 if served, otherwise from extensions/v1beta1 (Kubernetes 1.18 or below)
+// Empty string is returned if no Ingress routes to the workload, or the cluster serves neither of them
+func (c *Client) EnvironmentURL(workload Workload) (string, error) {
+	services, err := c.clientset.CoreV1().Services(workload.Namespace()).List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to retrieve Services")
+	}
+
+	targetServices := map[string]bool{}
+
+	for _, s := range services.Items {
+		if selectorMatches(s.Spec.Selector, workload.PodLabels()) {
+			targetServices[s.Name] = true
+		}
+	}
+
+	if len(targetServices) == 0 {
+		return "", nil
+	}
+
+	ingresses, err := c.listIngresses(workload.Namespace())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+
+		return "", errors.Wrap(err, "failed to retrieve Ingresses")
+	}
+
+	for _, ing := range ingresses {
+		tlsHosts := map[string]bool{}
+
+		for _, tls := range ing.Spec.TLS {
+			for _, h := range tls.Hosts {
+				tlsHosts[h] = true
+			}
+		}
+
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host == "" || !ingressRuleRoutesTo(ing.Spec.Backend, rule, targetServices) {
+				continue
+			}
+
+			if tlsHosts[rule.Host] {
+				return "https://" + rule.Host, nil
+			}
+
+			return "http://" + rule.Host, nil
+		}
+	}
+
+	return "", nil
+}
+
 // GetDeployment returns a deployment
 func (c *Client) GetDeployment(namespace, name string) (*Deployment, error) {
-	deployment, err := c.clientset.ExtensionsV1beta1().Deployments(namespace).Get(name)
+	deployment, err := c.getDeployment(namespace, name)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to retrieve Deployment %q", name)
 	}
@@ -140,9 +313,42 @@ func (c *Client) GetDeployment(namespace, name string) (*Deployment, error) {
 	return NewDeployment(c.annotationPrefix, deployment), nil
 }
 
-// ListDeployments returns the list of deployment
-func (c *Client) ListDeployments(namespace string) ([]*Deployment, error) {
-	deployments, err := c.clientset.ExtensionsV1beta1().Deployments(namespace).List(v1.ListOptions{})
+// GetWorkload returns the workload of the given kind and name
+func (c *Client) GetWorkload(namespace, kind, name string) (Workload, error) {
+	workload, err := c.getWorkload(namespace, kind, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve %s %q", kind, name)
+	}
+
+	return workload, nil
+}
+
+// ListCronJobs returns the list of CronJobs matched to the given label selector
+// CronJobs are read from batch/v1 if served, otherwise from batch/v1beta1 (Kubernetes 1.20 or below)
+// ErrCronJobsNotServed is returned with empty list if the cluster serves neither of them
+func (c *Client) ListCronJobs(namespace, selector string) ([]*CronJob, error) {
+	cronJobs, err := c.listCronJobs(namespace, selector)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return []*CronJob{}, ErrCronJobsNotServed
+		}
+
+		return []*CronJob{}, errors.Wrap(err, "failed to retrieve CronJobs")
+	}
+
+	cjs := make([]*CronJob, 0, len(cronJobs))
+
+	for i := range cronJobs {
+		cjs = append(cjs, NewCronJob(c.annotationPrefix, &cronJobs[i]))
+	}
+
+	return cjs, nil
+}
+
+// ListDeployments returns the list of deployment matched to the given label selector
+// All Deployments are returned if selector is empty
+func (c *Client) ListDeployments(namespace, selector string) ([]*Deployment, error) {
+	deployments, err := c.listDeployments(namespace, selector)
 	if err != nil {
 		return []*Deployment{}, errors.Wrap(err, "failed to retrieve Deployments")
 	}
@@ -150,23 +356,28 @@ func (c *Client) ListDeployments(namespace string) ([]*Deployment, error) {
 	ds := []*Deployment{}
 
 	// `for _, d := range deployments` uses the same pointer in `d`
-	for i := range deployments.Items {
-		ds = append(ds, NewDeployment(c.annotationPrefix, &deployments.Items[i]))
+	for i := range deployments {
+		ds = append(ds, NewDeployment(c.annotationPrefix, &deployments[i]))
 	}
 
 	return ds, nil
 }
 
+// ListPods returns the list of Pods controlled by the given ReplicaSet
+func (c *Client) ListPods(replicaSet *ReplicaSet) ([]*Pod, error) {
+	return c.listOwnedPods(replicaSet.Namespace(), replicaSet.UID())
+}
+
 // ListReplicaSets returns the list of ReplicaSets
 func (c *Client) ListReplicaSets(deployment *Deployment) ([]*ReplicaSet, error) {
-	all, err := c.clientset.ExtensionsV1beta1().ReplicaSets(deployment.Namespace()).List(v1.ListOptions{})
+	all, err := c.listReplicaSets(deployment.Namespace())
 	if err != nil {
 		return []*ReplicaSet{}, errors.Wrapf(err, "failed to retrieve ReplicaSets")
 	}
 
-	filtered := make([]*ReplicaSet, 0, len(all.Items))
+	filtered := make([]*ReplicaSet, 0, len(all))
 
-	for _, rs := range all.Items {
+	for _, rs := range all {
 		for _, or := range rs.GetOwnerReferences() {
 			if string(or.UID) == deployment.UID() {
 				r := rs
@@ -178,8 +389,84 @@ func (c *Client) ListReplicaSets(deployment *Deployment) ([]*ReplicaSet, error)
 	return filtered, nil
 }
 
-// ReloadPods reloads all Pods in the given deployment by setting new annotation
-func (c *Client) ReloadPods(deployment *Deployment, user, signature string) (*Deployment, error) {
+// ListRevisions returns the list of Revisions of the given workload
+// ReplicaSets are returned for Deployment, ControllerRevisions for StatefulSet and DaemonSet
+func (c *Client) ListRevisions(workload Workload) ([]Revision, error) {
+	if d, ok := workload.(*Deployment); ok {
+		rs, err := c.ListReplicaSets(d)
+		if err != nil {
+			return []Revision{}, err
+		}
+
+		revisions := make([]Revision, 0, len(rs))
+
+		for _, r := range rs {
+			revisions = append(revisions, r)
+		}
+
+		return revisions, nil
+	}
+
+	all, err := c.clientset.AppsV1().ControllerRevisions(workload.Namespace()).List(metav1.ListOptions{})
+	if err != nil {
+		return []Revision{}, errors.Wrap(err, "failed to retrieve ControllerRevisions")
+	}
+
+	revisions := make([]Revision, 0, len(all.Items))
+
+	for i := range all.Items {
+		for _, or := range all.Items[i].GetOwnerReferences() {
+			if string(or.UID) == workload.UID() {
+				revisions = append(revisions, NewControllerRevision(c.annotationPrefix, &all.Items[i]))
+			}
+		}
+	}
+
+	return revisions, nil
+}
+
+// ListWorkloads returns the list of Deployments, StatefulSets and DaemonSets in this order
+// matched to the given label selector
+// StatefulSets and DaemonSets are not listed in the cluster which does not serve apps/v1
+func (c *Client) ListWorkloads(namespace, selector string) ([]Workload, error) {
+	ds, err := c.ListDeployments(namespace, selector)
+	if err != nil {
+		return []Workload{}, err
+	}
+
+	workloads := make([]Workload, 0, len(ds))
+
+	for _, d := range ds {
+		workloads = append(workloads, d)
+	}
+
+	if c.legacyAppsAPI {
+		return workloads, nil
+	}
+
+	statefulSets, err := c.clientset.AppsV1().StatefulSets(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return []Workload{}, errors.Wrap(err, "failed to retrieve StatefulSets")
+	}
+
+	for i := range statefulSets.Items {
+		workloads = append(workloads, NewStatefulSet(c.annotationPrefix, &statefulSets.Items[i]))
+	}
+
+	daemonSets, err := c.clientset.AppsV1().DaemonSets(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return []Workload{}, errors.Wrap(err, "failed to retrieve DaemonSets")
+	}
+
+	for i := range daemonSets.Items {
+		workloads = append(workloads, NewDaemonSet(c.annotationPrefix, &daemonSets.Items[i]))
+	}
+
+	return workloads, nil
+}
+
+// ReloadPods reloads all Pods in the given workload by setting new annotation
+func (c *Client) ReloadPods(workload Workload, user, signature string) (Workload, error) {
 	patch := fmt.Sprintf(`{
   "spec": {
     "template": {
@@ -193,16 +480,22 @@ func (c *Client) ReloadPods(deployment *Deployment, user, signature string) (*De
   }
 }`, c.annotationPrefix+deployUserAnnotation, user, c.annotationPrefix+reloadedAtAnnotation, signature)
 
-	newd, err := c.clientset.ExtensionsV1beta1().Deployments(deployment.Namespace()).Patch(deployment.Name(), api.StrategicMergePatchType, []byte(patch))
+	neww, err := c.patchWorkload(workload, []byte(patch))
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to update deployment %q", deployment.Name())
+		return nil, errors.Wrapf(err, "failed to update %s %q", workload.Kind(), workload.Name())
 	}
 
-	return NewDeployment(c.annotationPrefix, newd), nil
+	return neww, nil
 }
 
-// SetImage sets new image to the given deployments
-func (c *Client) SetImage(deployment *Deployment, container, image, user, cause string) (*Deployment, error) {
+// SetImage sets new images to the containers of the given workload in one patch
+// images must be keyed by container name
+func (c *Client) SetImage(workload Workload, images map[string]string, user, cause string) (Workload, error) {
+	containers, err := containersPatch(images)
+	if err != nil {
+		return nil, err
+	}
+
 	patch := fmt.Sprintf(`{
   "metadata": {
     "annotations": {
@@ -217,21 +510,157 @@ func (c *Client) SetImage(deployment *Deployment, container, image, user, cause
         }
       },
       "spec": {
-        "containers": [
-          {
-            "name": "%s",
-            "image": "%s"
+        "containers": %s
+      }
+    }
+  }
+}`, changeCauseAnnotation, cause, c.annotationPrefix+deployUserAnnotation, user, containers)
+
+	neww, err := c.patchWorkload(workload, []byte(patch))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update %s %q", workload.Kind(), workload.Name())
+	}
+
+	return neww, nil
+}
+
+// listOwnedPods returns the list of Pods owned by the object of the given UID
+func (c *Client) listOwnedPods(namespace, uid string) ([]*Pod, error) {
+	all, err := c.clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return []*Pod{}, errors.Wrap(err, "failed to retrieve Pods")
+	}
+
+	filtered := make([]*Pod, 0, len(all.Items))
+
+	for i := range all.Items {
+		for _, or := range all.Items[i].GetOwnerReferences() {
+			if string(or.UID) == uid {
+				filtered = append(filtered, NewPod(&all.Items[i]))
+			}
+		}
+	}
+
+	return filtered, nil
+}
+
+// SetCronJobImage sets new images to the containers in the Job template of the given CronJob in one patch
+// images must be keyed by container name
+// Jobs already created by CronJob are not updated because Pod template of Job is immutable
+func (c *Client) SetCronJobImage(cronJob *CronJob, images map[string]string, user, cause string) (*CronJob, error) {
+	containers, err := containersPatch(images)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := fmt.Sprintf(`{
+  "metadata": {
+    "annotations": {
+      "%s": %q
+    }
+  },
+  "spec": {
+    "jobTemplate": {
+      "spec": {
+        "template": {
+          "metadata": {
+            "annotations": {
+              "%s": %q
+            }
+          },
+          "spec": {
+            "containers": %s
           }
-        ]
+        }
       }
     }
   }
-}`, changeCauseAnnotation, cause, c.annotationPrefix+deployUserAnnotation, user, container, image)
+}`, changeCauseAnnotation, cause, c.annotationPrefix+deployUserAnnotation, user, containers)
+
+	newj, err := c.patchCronJob(cronJob.Namespace(), cronJob.Name(), []byte(patch))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update CronJob %q", cronJob.Name())
+	}
+
+	return NewCronJob(c.annotationPrefix, newj), nil
+}
+
+// containersPatch returns the list of containers with new images in JSON
+// Containers are merged by name in strategic merge patch, and sorted by name to make the patch stable
+func containersPatch(images map[string]string) (string, error) {
+	if len(images) == 0 {
+		return "", errors.New("no image to set")
+	}
+
+	names := make([]string, 0, len(images))
+
+	for name := range images {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	containers := make([]map[string]string, 0, len(names))
+
+	for _, name := range names {
+		containers = append(containers, map[string]string{
+			"name":  name,
+			"image": images[name],
+		})
+	}
 
-	newd, err := c.clientset.ExtensionsV1beta1().Deployments(deployment.Namespace()).Patch(deployment.Name(), api.StrategicMergePatchType, []byte(patch))
+	b, err := json.Marshal(containers)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to update deployment %q", deployment.Name())
+		return "", errors.Wrap(err, "failed to encode containers")
+	}
+
+	return string(b), nil
+}
+
+// detectResource returns whether the cluster serves the resource in the given API group version
+func detectResource(clientset kubernetes.Interface, groupVersion, resource string) (bool, error) {
+	resources, err := clientset.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, errors.Wrapf(err, "failed to discover API group %q", groupVersion)
+	}
+
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func ingressRuleRoutesTo(defaultBackend *v1beta1.IngressBackend, rule v1beta1.IngressRule, services map[string]bool) bool {
+	if rule.HTTP == nil {
+		return defaultBackend != nil && services[defaultBackend.ServiceName]
+	}
+
+	for _, p := range rule.HTTP.Paths {
+		if services[p.Backend.ServiceName] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func selectorMatches(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
 	}
 
-	return NewDeployment(c.annotationPrefix, newd), nil
+	return true
 }
@@ -1,39 +1,57 @@
 package kubernetes
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
-	"k8s.io/client-go/pkg/api/v1"
-	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
-	"k8s.io/client-go/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestCurrentContext(t *testing.T) {
-	// TODO
+	client := &Client{
+		context: "asia-northeast1",
+	}
+
+	got, err := client.CurrentContext()
+	if err != nil {
+		t.Errorf("got error: %s", err)
+	}
+
+	expected := "asia-northeast1"
+	if got != expected {
+		t.Errorf("expected: %q, got: %q", expected, got)
+	}
 }
 
 func TestDetectTargetContainer_with_name(t *testing.T) {
-	deployment := &Deployment{
-		raw: &v1beta1.Deployment{
-			ObjectMeta: v1.ObjectMeta{
-				Name:      "deployment",
-				Namespace: "default",
-			},
-			Spec: v1beta1.DeploymentSpec{
-				Template: v1.PodTemplateSpec{
-					Spec: v1.PodSpec{
-						Containers: []v1.Container{
-							v1.Container{
-								Name: "rails",
-							},
+	deployment := NewDeployment("", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						v1.Container{
+							Name: "rails",
 						},
 					},
 				},
 			},
 		},
-	}
+	})
 
 	client := &Client{}
 
@@ -85,50 +103,46 @@ func TestDetectTargetContainer_without_name(t *testing.T) {
 		errMsg     string
 	}{
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-					},
-					Spec: v1beta1.DeploymentSpec{
-						Template: v1.PodTemplateSpec{
-							Spec: v1.PodSpec{
-								Containers: []v1.Container{
-									v1.Container{
-										Name: "rails",
-									},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+				},
+				Spec: appsv1.DeploymentSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								v1.Container{
+									Name: "rails",
 								},
 							},
 						},
 					},
 				},
-			},
+			}),
 			expectErr: false,
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-					},
-					Spec: v1beta1.DeploymentSpec{
-						Template: v1.PodTemplateSpec{
-							Spec: v1.PodSpec{
-								Containers: []v1.Container{
-									v1.Container{
-										Name: "rails",
-									},
-									v1.Container{
-										Name: "foobar",
-									},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+				},
+				Spec: appsv1.DeploymentSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								v1.Container{
+									Name: "rails",
+								},
+								v1.Container{
+									Name: "foobar",
 								},
 							},
 						},
 					},
 				},
-			},
+			}),
 			expectErr: true,
 			errMsg:    `multiple containers ["rails" "foobar"] found in deployment "deployment"`,
 		},
@@ -159,8 +173,8 @@ func TestDetectTargetContainer_without_name(t *testing.T) {
 }
 
 func TestDetectTargetDeployment_with_name(t *testing.T) {
-	deployment := &v1beta1.Deployment{
-		ObjectMeta: v1.ObjectMeta{
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment",
 			Namespace: "default",
 		},
@@ -216,14 +230,14 @@ func TestDetectTargetDeployment_with_name(t *testing.T) {
 
 func TestDetectTargetDeployment_without_name(t *testing.T) {
 	testcases := []struct {
-		deployments []v1beta1.Deployment
+		deployments []appsv1.Deployment
 		expectErr   bool
 		errMsg      string
 	}{
 		{
-			deployments: []v1beta1.Deployment{
-				v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
+			deployments: []appsv1.Deployment{
+				appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
 						Name:      "deployment",
 						Namespace: "default",
 					},
@@ -232,20 +246,20 @@ func TestDetectTargetDeployment_without_name(t *testing.T) {
 			expectErr: false,
 		},
 		{
-			deployments: []v1beta1.Deployment{},
+			deployments: []appsv1.Deployment{},
 			expectErr:   true,
 			errMsg:      `no Deployment found in namespace "default"`,
 		},
 		{
-			deployments: []v1beta1.Deployment{
-				v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
+			deployments: []appsv1.Deployment{
+				appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
 						Name:      "deployment",
 						Namespace: "default",
 					},
 				},
-				v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
+				appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
 						Name:      "foobar",
 						Namespace: "default",
 					},
@@ -260,7 +274,7 @@ func TestDetectTargetDeployment_without_name(t *testing.T) {
 	namespace := "default"
 
 	for _, tc := range testcases {
-		clientset := fake.NewSimpleClientset(&v1beta1.DeploymentList{
+		clientset := fake.NewSimpleClientset(&appsv1.DeploymentList{
 			Items: tc.deployments,
 		})
 		client := &Client{
@@ -286,9 +300,337 @@ func TestDetectTargetDeployment_without_name(t *testing.T) {
 	}
 }
 
+func TestDetectTargetWorkload(t *testing.T) {
+	statefulSets := []*appsv1.StatefulSet{}
+	for _, name := range []string{"db", "cache"} {
+		statefulSets = append(statefulSets, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		})
+	}
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "agent",
+			Namespace: "default",
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(statefulSets[0], statefulSets[1], daemonSet)
+	client := &Client{
+		clientset: clientset,
+	}
+
+	testcases := []struct {
+		kind      string
+		name      string
+		expected  string
+		expectErr bool
+		errMsg    string
+	}{
+		{
+			kind:     KindStatefulSet,
+			name:     "db",
+			expected: "db",
+		},
+		{
+			kind:     KindDaemonSet,
+			name:     "",
+			expected: "agent",
+		},
+		{
+			kind:      KindDaemonSet,
+			name:      "db",
+			expectErr: true,
+			errMsg:    `failed to retrieve DaemonSet "db"`,
+		},
+		{
+			kind:      KindStatefulSet,
+			name:      "",
+			expectErr: true,
+			errMsg:    `multiple StatefulSets ["db" "cache"] found in namespace "default"`,
+		},
+		{
+			kind:      KindDeployment,
+			name:      "",
+			expectErr: true,
+			errMsg:    `no Deployment found in namespace "default"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		got, err := client.DetectTargetWorkload("default", tc.kind, tc.name)
+
+		if tc.expectErr {
+			if err == nil {
+				t.Error("got no error")
+				continue
+			}
+
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.errMsg)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+				continue
+			}
+
+			if got.Kind() != tc.kind || got.Name() != tc.expected {
+				t.Errorf("expected: %s/%s, got: %s", tc.kind, tc.expected, WorkloadKey(got))
+			}
+		}
+	}
+}
+
+func TestDockerConfigs(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "quay",
+				Namespace: "default",
+			},
+			Type: v1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				".dockerconfigjson": []byte(`{"auths":{"quay.io":{"auth":"ZHRhbjQ6c2VjcmV0"}}}`),
+			},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "opaque",
+				Namespace: "default",
+			},
+			Type: v1.SecretTypeOpaque,
+		},
+	)
+	client := &Client{
+		clientset: clientset,
+	}
+
+	deployment := NewDeployment("", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					ImagePullSecrets: []v1.LocalObjectReference{
+						v1.LocalObjectReference{
+							Name: "quay",
+						},
+						v1.LocalObjectReference{
+							Name: "opaque",
+						},
+					},
+				},
+			},
+		},
+	})
+
+	got, err := client.DockerConfigs(deployment)
+	if err != nil {
+		t.Errorf("got error: %s", err)
+		return
+	}
+
+	expectedLength := 1
+	if len(got) != expectedLength {
+		t.Errorf("expected length: %d, got: %d", expectedLength, len(got))
+	}
+}
+
+func TestEnvironmentURL(t *testing.T) {
+	services := []v1.Service{
+		v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Selector: map[string]string{
+					"app": "web",
+				},
+			},
+		},
+		v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "api",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Selector: map[string]string{
+					"app": "api",
+				},
+			},
+		},
+	}
+	ingresses := []v1beta1.Ingress{
+		v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "api",
+				Namespace: "default",
+			},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{
+					v1beta1.IngressRule{
+						Host: "api.example.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									v1beta1.HTTPIngressPath{
+										Backend: v1beta1.IngressBackend{
+											ServiceName: "api",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "default",
+			},
+			Spec: v1beta1.IngressSpec{
+				TLS: []v1beta1.IngressTLS{
+					v1beta1.IngressTLS{
+						Hosts: []string{"www.example.com"},
+					},
+				},
+				Rules: []v1beta1.IngressRule{
+					v1beta1.IngressRule{
+						Host: "www.example.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									v1beta1.HTTPIngressPath{
+										Backend: v1beta1.IngressBackend{
+											ServiceName: "web",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(&v1.ServiceList{
+		Items: services,
+	}, &v1beta1.IngressList{
+		Items: ingresses,
+	})
+	client := &Client{
+		clientset: clientset,
+	}
+
+	testcases := []struct {
+		labels   map[string]string
+		expected string
+	}{
+		{
+			labels: map[string]string{
+				"app":  "web",
+				"role": "web",
+			},
+			expected: "https://www.example.com",
+		},
+		{
+			labels: map[string]string{
+				"app": "api",
+			},
+			expected: "http://api.example.com",
+		},
+		{
+			labels: map[string]string{
+				"app": "worker",
+			},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testcases {
+		deployment := NewDeployment("", &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "deployment",
+				Namespace: "default",
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: tc.labels,
+					},
+				},
+			},
+		})
+
+		got, err := client.EnvironmentURL(deployment)
+		if err != nil {
+			t.Errorf("got error: %s", err)
+			continue
+		}
+
+		if got != tc.expected {
+			t.Errorf("expected: %q, got: %q", tc.expected, got)
+		}
+	}
+}
+
+func TestEnvironmentURL_ingressNotServed(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{
+				"app": "web",
+			},
+		},
+	})
+	clientset.PrependReactor("list", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(v1beta1.Resource("ingresses"), "")
+	})
+	client := &Client{
+		clientset: clientset,
+	}
+
+	deployment := NewDeployment("", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "web",
+					},
+				},
+			},
+		},
+	})
+
+	got, err := client.EnvironmentURL(deployment)
+	if err != nil {
+		t.Errorf("got error: %s", err)
+	}
+
+	if got != "" {
+		t.Errorf("expected: %q, got: %q", "", got)
+	}
+}
+
 func TestGetDeployment(t *testing.T) {
-	deployment := &v1beta1.Deployment{
-		ObjectMeta: v1.ObjectMeta{
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment",
 			Namespace: "default",
 		},
@@ -335,23 +677,64 @@ func TestGetDeployment(t *testing.T) {
 	}
 }
 
+func TestListCronJobs(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "batch",
+			Namespace: "default",
+		},
+	})
+	client := &Client{
+		clientset: clientset,
+	}
+
+	got, err := client.ListCronJobs("default", "")
+	if err != nil {
+		t.Errorf("got error: %s", err)
+	}
+
+	expectedLength := 1
+	if len(got) != expectedLength {
+		t.Errorf("expected length: %d, got: %d", expectedLength, len(got))
+	}
+}
+
+func TestListCronJobs_notServed(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "cronjobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(batchv1beta1.Resource("cronjobs"), "")
+	})
+	client := &Client{
+		clientset: clientset,
+	}
+
+	got, err := client.ListCronJobs("default", "")
+	if err != ErrCronJobsNotServed {
+		t.Errorf("expected error: %v, got: %v", ErrCronJobsNotServed, err)
+	}
+
+	if len(got) != 0 {
+		t.Errorf("expected length: 0, got: %d", len(got))
+	}
+}
+
 func TestListDeployments(t *testing.T) {
-	deployments := []v1beta1.Deployment{
-		v1beta1.Deployment{
-			ObjectMeta: v1.ObjectMeta{
+	deployments := []appsv1.Deployment{
+		appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
 				Name:      "deployment",
 				Namespace: "default",
 			},
 		},
-		v1beta1.Deployment{
-			ObjectMeta: v1.ObjectMeta{
+		appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
 				Name:      "foobar",
 				Namespace: "default",
 			},
 		},
 	}
 
-	clientset := fake.NewSimpleClientset(&v1beta1.DeploymentList{
+	clientset := fake.NewSimpleClientset(&appsv1.DeploymentList{
 		Items: deployments,
 	})
 	client := &Client{
@@ -360,7 +743,7 @@ func TestListDeployments(t *testing.T) {
 
 	namespace := "default"
 
-	got, err := client.ListDeployments(namespace)
+	got, err := client.ListDeployments(namespace, "")
 	if err != nil {
 		t.Errorf("got error: %s", err)
 	}
@@ -371,25 +754,84 @@ func TestListDeployments(t *testing.T) {
 	}
 }
 
+func TestListPods(t *testing.T) {
+	pods := []v1.Pod{
+		v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "deployment-1234567890-abcde",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					metav1.OwnerReference{
+						UID: (types.UID)("0001"),
+					},
+				},
+			},
+		},
+		v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foobar-9876543210-fghij",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					metav1.OwnerReference{
+						UID: (types.UID)("0002"),
+					},
+				},
+			},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(&v1.PodList{
+		Items: pods,
+	})
+	client := &Client{
+		clientset: clientset,
+	}
+
+	replicaSet := &ReplicaSet{
+		raw: &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "deployment-1234567890",
+				Namespace: "default",
+				UID:       (types.UID)("0001"),
+			},
+		},
+	}
+
+	got, err := client.ListPods(replicaSet)
+	if err != nil {
+		t.Errorf("got error: %s", err)
+	}
+
+	expectedLength := 1
+	if len(got) != expectedLength {
+		t.Errorf("expected length: %d, got: %d", expectedLength, len(got))
+	}
+
+	expectedName := "deployment-1234567890-abcde"
+	if got[0].Name() != expectedName {
+		t.Errorf("expected: %q, got: %q", expectedName, got[0].Name())
+	}
+}
+
 func TestListReplicaSets(t *testing.T) {
-	replicasets := []v1beta1.ReplicaSet{
-		v1beta1.ReplicaSet{
-			ObjectMeta: v1.ObjectMeta{
+	replicasets := []appsv1.ReplicaSet{
+		appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
 				Name:      "deployment-1234567890",
 				Namespace: "default",
-				OwnerReferences: []v1.OwnerReference{
-					v1.OwnerReference{
+				OwnerReferences: []metav1.OwnerReference{
+					metav1.OwnerReference{
 						UID: (types.UID)("0001"),
 					},
 				},
 			},
 		},
-		v1beta1.ReplicaSet{
-			ObjectMeta: v1.ObjectMeta{
+		appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
 				Name:      "foobar-9876543210",
 				Namespace: "default",
-				OwnerReferences: []v1.OwnerReference{
-					v1.OwnerReference{
+				OwnerReferences: []metav1.OwnerReference{
+					metav1.OwnerReference{
 						UID: (types.UID)("0002"),
 					},
 				},
@@ -397,33 +839,31 @@ func TestListReplicaSets(t *testing.T) {
 		},
 	}
 
-	clientset := fake.NewSimpleClientset(&v1beta1.ReplicaSetList{
+	clientset := fake.NewSimpleClientset(&appsv1.ReplicaSetList{
 		Items: replicasets,
 	})
 	client := &Client{
 		clientset: clientset,
 	}
 
-	deployment := &Deployment{
-		raw: &v1beta1.Deployment{
-			ObjectMeta: v1.ObjectMeta{
-				Name:      "deployment",
-				Namespace: "default",
-				UID:       (types.UID)("0001"),
-			},
-			Spec: v1beta1.DeploymentSpec{
-				Template: v1.PodTemplateSpec{
-					Spec: v1.PodSpec{
-						Containers: []v1.Container{
-							v1.Container{
-								Name: "rails",
-							},
+	deployment := NewDeployment("", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+			UID:       (types.UID)("0001"),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						v1.Container{
+							Name: "rails",
 						},
 					},
 				},
 			},
 		},
-	}
+	})
 
 	got, err := client.ListReplicaSets(deployment)
 	if err != nil {
@@ -441,13 +881,168 @@ func TestListReplicaSets(t *testing.T) {
 	}
 }
 
+func TestListRevisions(t *testing.T) {
+	revisions := []appsv1.ControllerRevision{
+		appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "worker-1111111111",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					metav1.OwnerReference{
+						UID: (types.UID)("0001"),
+					},
+				},
+			},
+			Revision: 1,
+		},
+		appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fluentd-2222222222",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					metav1.OwnerReference{
+						UID: (types.UID)("0002"),
+					},
+				},
+			},
+			Revision: 1,
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(&appsv1.ControllerRevisionList{
+		Items: revisions,
+	})
+	client := &Client{
+		clientset: clientset,
+	}
+
+	statefulSet := NewStatefulSet("", &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker",
+			Namespace: "default",
+			UID:       (types.UID)("0001"),
+		},
+	})
+
+	got, err := client.ListRevisions(statefulSet)
+	if err != nil {
+		t.Errorf("got error: %s", err)
+	}
+
+	expectedLength := 1
+	if len(got) != expectedLength {
+		t.Errorf("expected length: %d, got: %d", expectedLength, len(got))
+		return
+	}
+
+	expectedName := "worker-1111111111"
+	if got[0].Name() != expectedName {
+		t.Errorf("expected: %q, got: %q", expectedName, got[0].Name())
+	}
+}
+
+func TestListWorkloads(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "default",
+				Labels: map[string]string{
+					"role": "web",
+				},
+			},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "worker",
+				Namespace: "default",
+				Labels: map[string]string{
+					"role": "worker",
+				},
+			},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fluentd",
+				Namespace: "default",
+			},
+		},
+	)
+
+	testcases := []struct {
+		legacyAppsAPI bool
+		selector      string
+		expected      []string
+	}{
+		{
+			legacyAppsAPI: false,
+			selector:      "",
+			expected:      []string{"Deployment/web", "StatefulSet/worker", "DaemonSet/fluentd"},
+		},
+		{
+			legacyAppsAPI: false,
+			selector:      "role=worker",
+			expected:      []string{"StatefulSet/worker"},
+		},
+		{
+			legacyAppsAPI: false,
+			selector:      "role",
+			expected:      []string{"Deployment/web", "StatefulSet/worker"},
+		},
+		{
+			legacyAppsAPI: true,
+			selector:      "",
+			expected:      []string{"Deployment/web"},
+		},
+		{
+			legacyAppsAPI: true,
+			selector:      "role=worker",
+			expected:      []string{},
+		},
+	}
+
+	for _, tc := range testcases {
+		client := &Client{
+			clientset:     clientset,
+			legacyAppsAPI: tc.legacyAppsAPI,
+		}
+
+		if tc.legacyAppsAPI {
+			client.clientset = fake.NewSimpleClientset(&v1beta1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web",
+					Namespace: "default",
+					Labels: map[string]string{
+						"role": "web",
+					},
+				},
+			})
+		}
+
+		got, err := client.ListWorkloads("default", tc.selector)
+		if err != nil {
+			t.Errorf("got error: %s", err)
+			continue
+		}
+
+		keys := []string{}
+		for _, w := range got {
+			keys = append(keys, WorkloadKey(w))
+		}
+
+		if !reflect.DeepEqual(keys, tc.expected) {
+			t.Errorf("expected: %q, got: %q", tc.expected, keys)
+		}
+	}
+}
+
 func TestReloadPods(t *testing.T) {
-	raw := &v1beta1.Deployment{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment",
 			Namespace: "default",
 		},
-		Spec: v1beta1.DeploymentSpec{
+		Spec: appsv1.DeploymentSpec{
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
@@ -460,9 +1055,7 @@ func TestReloadPods(t *testing.T) {
 			},
 		},
 	}
-	deployment := &Deployment{
-		raw: raw,
-	}
+	deployment := NewDeployment("", raw)
 
 	clientset := fake.NewSimpleClientset(raw)
 	client := &Client{
@@ -482,12 +1075,12 @@ func TestReloadPods(t *testing.T) {
 }
 
 func TestSetImage(t *testing.T) {
-	raw := &v1beta1.Deployment{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment",
 			Namespace: "default",
 		},
-		Spec: v1beta1.DeploymentSpec{
+		Spec: appsv1.DeploymentSpec{
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
@@ -500,21 +1093,20 @@ func TestSetImage(t *testing.T) {
 			},
 		},
 	}
-	deployment := &Deployment{
-		raw: raw,
-	}
+	deployment := NewDeployment("", raw)
 
 	clientset := fake.NewSimpleClientset(raw)
 	client := &Client{
 		clientset: clientset,
 	}
 
-	container := "rails"
-	image := "my-rails:v3"
+	images := map[string]string{
+		"rails": "my-rails:v3",
+	}
 	user := "dtan4"
 	cause := "k8ship test"
 
-	_, err := client.SetImage(deployment, container, image, user, cause)
+	_, err := client.SetImage(deployment, images, user, cause)
 	if err != nil {
 		t.Errorf("got error: %s", err)
 		return
@@ -522,3 +1114,39 @@ func TestSetImage(t *testing.T) {
 
 	// Unfortunally, there is no way to check the updated Deployment image...
 }
+
+func TestSetCronJobImage(t *testing.T) {
+	raw := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "batch",
+			Namespace: "default",
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								v1.Container{
+									Name:  "rake",
+									Image: "my-rails:v2",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	cronJob := NewCronJob("", raw)
+
+	clientset := fake.NewSimpleClientset(raw)
+	client := &Client{
+		clientset: clientset,
+	}
+
+	_, err := client.SetCronJobImage(cronJob, map[string]string{"rake": "my-rails:v3"}, "dtan4", "k8ship test")
+	if err != nil {
+		t.Errorf("got error: %s", err)
+	}
+}
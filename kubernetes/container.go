@@ -1,7 +1,7 @@
 package kubernetes
 
 import (
-	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/api/core/v1"
 )
 
 // Container represents the wrapper of Kubernetes Pod container
@@ -3,7 +3,7 @@ package kubernetes
 import (
 	"testing"
 
-	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/api/core/v1"
 )
 
 func TestContainerImage(t *testing.T) {
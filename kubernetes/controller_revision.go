@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+)
+
+// ControllerRevision represents the wrapper of Kubernetes ControllerRevision
+// ControllerRevision holds the Pod template of StatefulSet or DaemonSet at the revision
+type ControllerRevision struct {
+	annotationPrefix string
+	raw              *appsv1.ControllerRevision
+}
+
+// controllerRevisionData represents the Pod template patch stored in ControllerRevision
+type controllerRevisionData struct {
+	Spec struct {
+		Template v1.PodTemplateSpec `json:"template"`
+	} `json:"spec"`
+}
+
+// NewControllerRevision creates new ControllerRevision object
+func NewControllerRevision(annotationPrefix string, raw *appsv1.ControllerRevision) *ControllerRevision {
+	return &ControllerRevision{
+		annotationPrefix: annotationPrefix,
+		raw:              raw,
+	}
+}
+
+// CreatedAt returns the creation timestamp
+func (r *ControllerRevision) CreatedAt() time.Time {
+	return r.raw.CreationTimestamp.Time
+}
+
+// DeployUser returns the deploy user
+func (r *ControllerRevision) DeployUser() string {
+	return r.template().Annotations[r.annotationPrefix+deployUserAnnotation]
+}
+
+// Images returns the list of deployed images at the moment
+func (r *ControllerRevision) Images() map[string]string {
+	images := map[string]string{}
+
+	for _, c := range r.template().Spec.Containers {
+		images[c.Name] = c.Image
+	}
+
+	return images
+}
+
+// Hash returns the hash of Pod template in `controller-revision-hash` label
+// Pods created from this revision have the same label
+func (r *ControllerRevision) Hash() string {
+	return r.raw.Labels[controllerRevisionHashLabel]
+}
+
+// Name returns the name of ControllerRevision
+func (r *ControllerRevision) Name() string {
+	return r.raw.Name
+}
+
+// Revision returns the revision signature
+func (r *ControllerRevision) Revision() string {
+	return strconv.FormatInt(r.raw.Revision, 10)
+}
+
+// RevisionNumber returns the revision as number
+func (r *ControllerRevision) RevisionNumber() (int64, error) {
+	return r.raw.Revision, nil
+}
+
+// template returns the Pod template stored in ControllerRevision
+// empty template is returned if the data cannot be decoded
+func (r *ControllerRevision) template() *v1.PodTemplateSpec {
+	var data controllerRevisionData
+
+	if err := json.Unmarshal(r.raw.Data.Raw, &data); err != nil {
+		return &v1.PodTemplateSpec{}
+	}
+
+	return &data.Spec.Template
+}
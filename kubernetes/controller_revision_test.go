@@ -0,0 +1,48 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestControllerRevision(t *testing.T) {
+	r := &ControllerRevision{
+		raw: &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"controller-revision-hash": "2222222222",
+				},
+				Name:      "worker-2222222222",
+				Namespace: "default",
+			},
+			Data: runtime.RawExtension{
+				Raw: []byte(`{"spec":{"template":{"$patch":"replace","metadata":{"annotations":{"deploy-user":"dtan4"}},"spec":{"containers":[{"name":"worker","image":"my-worker:v2"},{"name":"sidecar","image":"sidecar:latest"}]}}}}`),
+			},
+			Revision: 3,
+		},
+	}
+
+	if got, want := r.Revision(), "3"; got != want {
+		t.Errorf("expected: %q, got: %q", want, got)
+	}
+
+	if got, want := r.Hash(), "2222222222"; got != want {
+		t.Errorf("expected: %q, got: %q", want, got)
+	}
+
+	if got, want := r.DeployUser(), "dtan4"; got != want {
+		t.Errorf("expected: %q, got: %q", want, got)
+	}
+
+	wantImages := map[string]string{
+		"worker":  "my-worker:v2",
+		"sidecar": "sidecar:latest",
+	}
+	if got := r.Images(); !reflect.DeepEqual(got, wantImages) {
+		t.Errorf("expected: %q, got: %q", wantImages, got)
+	}
+}
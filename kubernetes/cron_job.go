@@ -0,0 +1,24 @@
+package kubernetes
+
+import (
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+)
+
+// KindCronJob is the kind of CronJob
+const KindCronJob = "CronJob"
+
+// CronJob represents the wrapper of Kubernetes CronJob
+// CronJob is updated together with Workloads, but has no rollout
+// CronJob in batch/v1 is also held as batch/v1beta1 object, which has the compatible JSON schema
+type CronJob struct {
+	podTemplateObject
+	raw *batchv1beta1.CronJob
+}
+
+// NewCronJob creates new CronJob object
+func NewCronJob(annotationPrefix string, raw *batchv1beta1.CronJob) *CronJob {
+	return &CronJob{
+		podTemplateObject: newPodTemplateObject(annotationPrefix, KindCronJob, &raw.ObjectMeta, &raw.Spec.JobTemplate.Spec.Template),
+		raw:               raw,
+	}
+}
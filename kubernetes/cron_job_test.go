@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCronJobDeployTargetContainers(t *testing.T) {
+	testcases := []struct {
+		annotations map[string]string
+		expectErr   bool
+		expected    string
+		errMsg      string
+	}{
+		{
+			annotations: map[string]string{
+				"deploy-target":           "true",
+				"deploy-target-container": "rake",
+			},
+			expectErr: false,
+			expected:  "rake",
+		},
+		{
+			annotations: map[string]string{
+				"deploy-target": "true",
+			},
+			expectErr: true,
+			errMsg:    `annotation "deploy-target-container" does not exist in CronJob "batch"`,
+		},
+		{
+			annotations: map[string]string{
+				"deploy-target":           "true",
+				"deploy-target-container": "foo",
+			},
+			expectErr: true,
+			errMsg:    `container "foo" does not exist in CronJob "batch"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		cronJob := NewCronJob("", &batchv1beta1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "batch",
+				Namespace:   "default",
+				Annotations: tc.annotations,
+			},
+			Spec: batchv1beta1.CronJobSpec{
+				JobTemplate: batchv1beta1.JobTemplateSpec{
+					Spec: batchv1.JobSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									v1.Container{
+										Name:  "rake",
+										Image: "my-rails:v2",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+
+		if !cronJob.IsDeployTarget() {
+			t.Errorf("CronJob must be deploy target")
+		}
+
+		got, err := cronJob.DeployTargetContainers()
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+				continue
+			}
+
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.errMsg)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+				continue
+			}
+
+			if len(got) != 1 || got[0].Name() != tc.expected {
+				t.Errorf("expected: [%q], got: %v", tc.expected, got)
+			}
+		}
+	}
+}
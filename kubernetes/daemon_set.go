@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// DaemonSet represents the wrapper of Kubernetes DaemonSet
+type DaemonSet struct {
+	podTemplateObject
+	raw *appsv1.DaemonSet
+}
+
+// NewDaemonSet creates new DaemonSet object
+func NewDaemonSet(annotationPrefix string, raw *appsv1.DaemonSet) *DaemonSet {
+	return &DaemonSet{
+		podTemplateObject: newPodTemplateObject(annotationPrefix, KindDaemonSet, &raw.ObjectMeta, &raw.Spec.Template),
+		raw:               raw,
+	}
+}
+
+// RolloutStatus returns whether the latest rollout of DaemonSet has been completed,
+// and the message which describes the current progress
+func (d *DaemonSet) RolloutStatus() (bool, string, error) {
+	if d.raw.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return true, fmt.Sprintf("rollout status is not available for %s strategy", d.raw.Spec.UpdateStrategy.Type), nil
+	}
+
+	if d.raw.Generation > d.raw.Status.ObservedGeneration {
+		return false, "waiting for daemon set spec update to be observed", nil
+	}
+
+	if d.raw.Status.UpdatedNumberScheduled < d.raw.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%d out of %d new pods have been updated", d.raw.Status.UpdatedNumberScheduled, d.raw.Status.DesiredNumberScheduled), nil
+	}
+
+	if d.raw.Status.NumberAvailable < d.raw.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%d of %d updated pods are available", d.raw.Status.NumberAvailable, d.raw.Status.DesiredNumberScheduled), nil
+	}
+
+	return true, "successfully rolled out", nil
+}
@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDaemonSetRolloutStatus(t *testing.T) {
+	testcases := []struct {
+		strategy appsv1.DaemonSetUpdateStrategyType
+		status   appsv1.DaemonSetStatus
+		expected bool
+	}{
+		{
+			strategy: appsv1.RollingUpdateDaemonSetStrategyType,
+			status: appsv1.DaemonSetStatus{
+				ObservedGeneration:     2,
+				DesiredNumberScheduled: 3,
+				UpdatedNumberScheduled: 3,
+				NumberAvailable:        3,
+			},
+			expected: true,
+		},
+		{
+			strategy: appsv1.RollingUpdateDaemonSetStrategyType,
+			status: appsv1.DaemonSetStatus{
+				ObservedGeneration:     1,
+				DesiredNumberScheduled: 3,
+				UpdatedNumberScheduled: 3,
+				NumberAvailable:        3,
+			},
+			expected: false,
+		},
+		{
+			strategy: appsv1.RollingUpdateDaemonSetStrategyType,
+			status: appsv1.DaemonSetStatus{
+				ObservedGeneration:     2,
+				DesiredNumberScheduled: 3,
+				UpdatedNumberScheduled: 1,
+				NumberAvailable:        3,
+			},
+			expected: false,
+		},
+		{
+			strategy: appsv1.RollingUpdateDaemonSetStrategyType,
+			status: appsv1.DaemonSetStatus{
+				ObservedGeneration:     2,
+				DesiredNumberScheduled: 3,
+				UpdatedNumberScheduled: 3,
+				NumberAvailable:        2,
+			},
+			expected: false,
+		},
+		{
+			strategy: appsv1.OnDeleteDaemonSetStrategyType,
+			status:   appsv1.DaemonSetStatus{},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		daemonSet := NewDaemonSet("", &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "fluentd",
+				Namespace:  "default",
+				Generation: 2,
+			},
+			Spec: appsv1.DaemonSetSpec{
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+					Type: tc.strategy,
+				},
+			},
+			Status: tc.status,
+		})
+
+		got, _, err := daemonSet.RolloutStatus()
+		if err != nil {
+			t.Errorf("got error: %s", err)
+		}
+
+		if got != tc.expected {
+			t.Errorf("expected: %t, got: %t", tc.expected, got)
+		}
+	}
+}
@@ -5,13 +5,14 @@ import (
 	"strings"
 	"testing"
 
-	"k8s.io/client-go/pkg/api/v1"
-	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestDeploymentAnnotations(t *testing.T) {
-	raw := &v1beta1.Deployment{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment",
 			Namespace: "default",
 			Annotations: map[string]string{
@@ -24,7 +25,7 @@ func TestDeploymentAnnotations(t *testing.T) {
 				"color": "blue",
 			},
 		},
-		Spec: v1beta1.DeploymentSpec{
+		Spec: appsv1.DeploymentSpec{
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
@@ -37,9 +38,7 @@ func TestDeploymentAnnotations(t *testing.T) {
 			},
 		},
 	}
-	deployment := &Deployment{
-		raw: raw,
-	}
+	deployment := NewDeployment("", raw)
 
 	expected := map[string]string{
 		"deploy-target":           "1",
@@ -52,8 +51,8 @@ func TestDeploymentAnnotations(t *testing.T) {
 }
 
 func TestDeploymentContainers(t *testing.T) {
-	raw := &v1beta1.Deployment{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment",
 			Namespace: "default",
 			Annotations: map[string]string{
@@ -66,7 +65,7 @@ func TestDeploymentContainers(t *testing.T) {
 				"color": "blue",
 			},
 		},
-		Spec: v1beta1.DeploymentSpec{
+		Spec: appsv1.DeploymentSpec{
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
@@ -79,9 +78,7 @@ func TestDeploymentContainers(t *testing.T) {
 			},
 		},
 	}
-	deployment := &Deployment{
-		raw: raw,
-	}
+	deployment := NewDeployment("", raw)
 
 	expected := []*Container{
 		&Container{
@@ -97,26 +94,24 @@ func TestDeploymentContainers(t *testing.T) {
 }
 
 func TestContainerImageFromDeployment(t *testing.T) {
-	deployment := &Deployment{
-		raw: &v1beta1.Deployment{
-			ObjectMeta: v1.ObjectMeta{
-				Name:      "deployment",
-				Namespace: "default",
-			},
-			Spec: v1beta1.DeploymentSpec{
-				Template: v1.PodTemplateSpec{
-					Spec: v1.PodSpec{
-						Containers: []v1.Container{
-							v1.Container{
-								Name:  "rails",
-								Image: "my-rails:v3",
-							},
+	deployment := NewDeployment("", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						v1.Container{
+							Name:  "rails",
+							Image: "my-rails:v3",
 						},
 					},
 				},
 			},
 		},
-	}
+	})
 
 	testcases := []struct {
 		container string
@@ -139,98 +134,152 @@ func TestContainerImageFromDeployment(t *testing.T) {
 	}
 }
 
-func TestDeployTargetContainer(t *testing.T) {
+func TestDeployTargetContainers(t *testing.T) {
 	testcases := []struct {
-		deployment   *Deployment
-		expectErr    bool
-		expectedName string
-		errMsg       string
+		deployment    *Deployment
+		expectErr     bool
+		expectedNames []string
+		errMsg        string
 	}{
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"deploy-target":           "1",
-							"deploy-target-container": "rails",
-						},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"deploy-target":           "1",
+						"deploy-target-container": "rails",
 					},
-					Spec: v1beta1.DeploymentSpec{
-						Template: v1.PodTemplateSpec{
-							Spec: v1.PodSpec{
-								Containers: []v1.Container{
-									v1.Container{
-										Name:  "rails",
-										Image: "my-rails:v3",
-									},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								v1.Container{
+									Name:  "rails",
+									Image: "my-rails:v3",
 								},
 							},
 						},
 					},
 				},
-			},
-			expectErr:    false,
-			expectedName: "rails",
+			}),
+			expectErr:     false,
+			expectedNames: []string{"rails"},
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"deploy-target":           "1",
-							"deploy-target-container": "nginx",
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"deploy-target":           "1",
+						"deploy-target-container": "rails, worker",
+					},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								v1.Container{
+									Name:  "nginx",
+									Image: "nginx:latest",
+								},
+								v1.Container{
+									Name:  "rails",
+									Image: "my-rails:v3",
+								},
+								v1.Container{
+									Name:  "worker",
+									Image: "my-rails:v3",
+								},
+							},
 						},
 					},
-					Spec: v1beta1.DeploymentSpec{
-						Template: v1.PodTemplateSpec{
-							Spec: v1.PodSpec{
-								Containers: []v1.Container{
-									v1.Container{
-										Name:  "rails",
-										Image: "my-rails:v3",
-									},
+				},
+			}),
+			expectErr:     false,
+			expectedNames: []string{"rails", "worker"},
+		},
+		{
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"deploy-target":           "1",
+						"deploy-target-container": "",
+					},
+				},
+			}),
+			expectErr: true,
+			errMsg:    `annotation "deploy-target-container" is empty in Deployment "deployment"`,
+		},
+		{
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"deploy-target":           "1",
+						"deploy-target-container": "nginx",
+					},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								v1.Container{
+									Name:  "rails",
+									Image: "my-rails:v3",
 								},
 							},
 						},
 					},
 				},
-			},
+			}),
 			expectErr: true,
 			errMsg:    `container "nginx" does not exist in Deployment "deployment"`,
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:        "deployment",
-						Namespace:   "default",
-						Annotations: map[string]string{},
-					},
-					Spec: v1beta1.DeploymentSpec{
-						Template: v1.PodTemplateSpec{
-							Spec: v1.PodSpec{
-								Containers: []v1.Container{
-									v1.Container{
-										Name:  "rails",
-										Image: "my-rails:v3",
-									},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "deployment",
+					Namespace:   "default",
+					Annotations: map[string]string{},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								v1.Container{
+									Name:  "rails",
+									Image: "my-rails:v3",
 								},
 							},
 						},
 					},
 				},
-			},
+			}),
 			expectErr: true,
 			errMsg:    `annotation "deploy-target-container" does not exist in Deployment "deployment"`,
+		}, {
+			deployment: NewDeployment("example.com/", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"example.com/deploy-target": "1",
+					},
+				},
+			}),
+			expectErr: true,
+			errMsg:    `annotation "example.com/deploy-target-container" does not exist in Deployment "deployment"`,
 		},
 	}
 
 	for _, tc := range testcases {
-		got, err := tc.deployment.DeployTargetContainer()
+		got, err := tc.deployment.DeployTargetContainers()
 
 		if tc.expectErr {
 			if err == nil {
@@ -246,8 +295,13 @@ func TestDeployTargetContainer(t *testing.T) {
 				t.Errorf("got error: %s", err)
 			}
 
-			if got.Name() != tc.expectedName {
-				t.Errorf("expected name: %q, got: %q", tc.expectedName, got.Name())
+			names := []string{}
+			for _, c := range got {
+				names = append(names, c.Name())
+			}
+
+			if !reflect.DeepEqual(names, tc.expectedNames) {
+				t.Errorf("expected names: %q, got: %q", tc.expectedNames, names)
 			}
 		}
 	}
@@ -259,73 +313,65 @@ func TestIsDeployTarget(t *testing.T) {
 		expected   bool
 	}{
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"deploy-target": "1",
-						},
-						Labels: map[string]string{
-							"app":   "rails-app",
-							"color": "blue",
-						},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"deploy-target": "1",
+					},
+					Labels: map[string]string{
+						"app":   "rails-app",
+						"color": "blue",
 					},
 				},
-			},
+			}),
 			expected: true,
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"deploy-target": "true",
-						},
-						Labels: map[string]string{
-							"app":   "rails-app",
-							"color": "blue",
-						},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"deploy-target": "true",
+					},
+					Labels: map[string]string{
+						"app":   "rails-app",
+						"color": "blue",
 					},
 				},
-			},
+			}),
 			expected: true,
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"deploy-target": "false",
-						},
-						Labels: map[string]string{
-							"app":   "rails-app",
-							"color": "blue",
-						},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"deploy-target": "false",
+					},
+					Labels: map[string]string{
+						"app":   "rails-app",
+						"color": "blue",
 					},
 				},
-			},
+			}),
 			expected: false,
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:        "deployment",
-						Namespace:   "default",
-						Annotations: map[string]string{},
-						Labels: map[string]string{
-							"app":   "rails-app",
-							"color": "blue",
-						},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "deployment",
+					Namespace:   "default",
+					Annotations: map[string]string{},
+					Labels: map[string]string{
+						"app":   "rails-app",
+						"color": "blue",
 					},
 				},
-			},
+			}),
 			expected: false,
 		},
 	}
@@ -338,8 +384,8 @@ func TestIsDeployTarget(t *testing.T) {
 }
 
 func TestDeploymentLabels(t *testing.T) {
-	raw := &v1beta1.Deployment{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment",
 			Namespace: "default",
 			Annotations: map[string]string{
@@ -352,7 +398,7 @@ func TestDeploymentLabels(t *testing.T) {
 				"color": "blue",
 			},
 		},
-		Spec: v1beta1.DeploymentSpec{
+		Spec: appsv1.DeploymentSpec{
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
@@ -365,9 +411,7 @@ func TestDeploymentLabels(t *testing.T) {
 			},
 		},
 	}
-	deployment := &Deployment{
-		raw: raw,
-	}
+	deployment := NewDeployment("", raw)
 
 	expected := map[string]string{
 		"app":   "rails-app",
@@ -379,8 +423,8 @@ func TestDeploymentLabels(t *testing.T) {
 }
 
 func TestDeploymentName(t *testing.T) {
-	raw := &v1beta1.Deployment{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment",
 			Namespace: "default",
 			Annotations: map[string]string{
@@ -393,7 +437,7 @@ func TestDeploymentName(t *testing.T) {
 				"color": "blue",
 			},
 		},
-		Spec: v1beta1.DeploymentSpec{
+		Spec: appsv1.DeploymentSpec{
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
@@ -406,9 +450,7 @@ func TestDeploymentName(t *testing.T) {
 			},
 		},
 	}
-	deployment := &Deployment{
-		raw: raw,
-	}
+	deployment := NewDeployment("", raw)
 
 	expected := "deployment"
 	if got := deployment.Name(); got != expected {
@@ -417,8 +459,8 @@ func TestDeploymentName(t *testing.T) {
 }
 
 func TestDeploymentNamespace(t *testing.T) {
-	raw := &v1beta1.Deployment{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment",
 			Namespace: "default",
 			Annotations: map[string]string{
@@ -431,7 +473,7 @@ func TestDeploymentNamespace(t *testing.T) {
 				"color": "blue",
 			},
 		},
-		Spec: v1beta1.DeploymentSpec{
+		Spec: appsv1.DeploymentSpec{
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
@@ -444,9 +486,7 @@ func TestDeploymentNamespace(t *testing.T) {
 			},
 		},
 	}
-	deployment := &Deployment{
-		raw: raw,
-	}
+	deployment := NewDeployment("", raw)
 
 	expected := "default"
 	if got := deployment.Namespace(); got != expected {
@@ -454,6 +494,59 @@ func TestDeploymentNamespace(t *testing.T) {
 	}
 }
 
+func TestRepository(t *testing.T) {
+	deployment := NewDeployment("", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"github": "rails=rails/rails,foobar=dtan4/foobar",
+			},
+		},
+	})
+
+	testcases := []struct {
+		container string
+		expectErr bool
+		expected  string
+		errMsg    string
+	}{
+		{
+			container: "foobar",
+			expectErr: false,
+			expected:  "dtan4/foobar",
+		},
+		{
+			container: "nginx",
+			expectErr: true,
+			errMsg:    `GitHub repository for container "nginx" not found in Deployment "deployment"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		got, err := deployment.Repository(tc.container)
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+				continue
+			}
+
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.errMsg)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected: %q, got: %q", tc.expected, got)
+			}
+		}
+	}
+}
+
 func TestRepositories(t *testing.T) {
 	testcases := []struct {
 		deployment *Deployment
@@ -462,34 +555,30 @@ func TestRepositories(t *testing.T) {
 		errMsg     string
 	}{
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"github": "rails=rails/rails",
-						},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"github": "rails=rails/rails",
 					},
 				},
-			},
+			}),
 			expectErr: false,
 			expected: map[string]string{
 				"rails": "rails/rails",
 			},
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"github": "rails=rails/rails,foobar=dtan4/foobar",
-						},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"github": "rails=rails/rails,foobar=dtan4/foobar",
 					},
 				},
-			},
+			}),
 			expectErr: false,
 			expected: map[string]string{
 				"rails":  "rails/rails",
@@ -497,45 +586,39 @@ func TestRepositories(t *testing.T) {
 			},
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:        "deployment",
-						Namespace:   "default",
-						Annotations: map[string]string{},
-					},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "deployment",
+					Namespace:   "default",
+					Annotations: map[string]string{},
 				},
-			},
+			}),
 			expectErr: true,
 			errMsg:    `annotation "github" not found in Deployment "deployment"`,
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"github": "rails=rails/rails,",
-						},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"github": "rails=rails/rails,",
 					},
 				},
-			},
+			}),
 			expectErr: true,
 			errMsg:    `invalid annotation "github" value "", must be "container=owner/repo"`,
 		},
 		{
-			deployment: &Deployment{
-				raw: &v1beta1.Deployment{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "deployment",
-						Namespace: "default",
-						Annotations: map[string]string{
-							"github": "foobarbaz",
-						},
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployment",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"github": "foobarbaz",
 					},
 				},
-			},
+			}),
 			expectErr: true,
 			errMsg:    `invalid annotation "github" value "foobarbaz", must be "container=owner/repo"`,
 		},
@@ -564,3 +647,185 @@ func TestRepositories(t *testing.T) {
 		}
 	}
 }
+
+func TestRolloutStatus(t *testing.T) {
+	replicas := int32(3)
+
+	testcases := []struct {
+		deployment *Deployment
+		expectErr  bool
+		expected   bool
+		errMsg     string
+	}{
+		{
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "deployment",
+					Namespace:  "default",
+					Generation: 2,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+				},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           3,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  3,
+				},
+			}),
+			expectErr: false,
+			expected:  true,
+		},
+		{
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "deployment",
+					Namespace:  "default",
+					Generation: 2,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+				},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 1,
+					Replicas:           3,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  3,
+				},
+			}),
+			expectErr: false,
+			expected:  false,
+		},
+		{
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "deployment",
+					Namespace:  "default",
+					Generation: 2,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+				},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           4,
+					UpdatedReplicas:    1,
+					AvailableReplicas:  3,
+				},
+			}),
+			expectErr: false,
+			expected:  false,
+		},
+		{
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "deployment",
+					Namespace:  "default",
+					Generation: 2,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+				},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           3,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  2,
+				},
+			}),
+			expectErr: false,
+			expected:  false,
+		},
+		{
+			deployment: NewDeployment("", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "deployment",
+					Namespace:  "default",
+					Generation: 2,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+				},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           4,
+					UpdatedReplicas:    1,
+					AvailableReplicas:  3,
+					Conditions: []appsv1.DeploymentCondition{
+						appsv1.DeploymentCondition{
+							Type:    appsv1.DeploymentProgressing,
+							Status:  v1.ConditionFalse,
+							Reason:  "ProgressDeadlineExceeded",
+							Message: `ReplicaSet "deployment-1234567890" has timed out progressing.`,
+						},
+					},
+				},
+			}),
+			expectErr: true,
+			errMsg:    `rollout of Deployment "deployment" failed: ProgressDeadlineExceeded`,
+		},
+	}
+
+	for _, tc := range testcases {
+		got, _, err := tc.deployment.RolloutStatus()
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+				continue
+			}
+
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.errMsg)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected: %t, got: %t", tc.expected, got)
+			}
+		}
+	}
+}
+
+func TestPinDigest(t *testing.T) {
+	testcases := []struct {
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			annotations: map[string]string{
+				"pin-digest": "true",
+			},
+			expected: true,
+		},
+		{
+			annotations: map[string]string{
+				"pin-digest": "0",
+			},
+			expected: false,
+		},
+		{
+			annotations: map[string]string{},
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testcases {
+		deployment := NewDeployment("", &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "deployment",
+				Namespace:   "default",
+				Annotations: tc.annotations,
+			},
+		})
+
+		got := deployment.PinDigest()
+		if got != tc.expected {
+			t.Errorf("expected: %t, got: %t", tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package kubernetes
+
+// GitHubAnnotations represents the GitHub Deployment settings in the annotations of workload
+// nil (or empty) field means that the annotation is not set
+type GitHubAnnotations struct {
+	AutoInactive          *bool
+	Environment           string
+	ProductionEnvironment *bool
+	RequiredContexts      []string
+	TransientEnvironment  *bool
+}
+
+// GitHubAnnotations returns the values of `github-*` annotations in the given workload
+func (c *Client) GitHubAnnotations(workload Workload) *GitHubAnnotations {
+	annotations := workload.Annotations()
+
+	return &GitHubAnnotations{
+		AutoInactive:          boolAnnotation(annotations, c.annotationPrefix+githubAutoInactiveAnnotation),
+		Environment:           annotations[c.annotationPrefix+githubEnvironmentAnnotation],
+		ProductionEnvironment: boolAnnotation(annotations, c.annotationPrefix+githubProductionEnvironmentAnnotation),
+		RequiredContexts:      listAnnotation(annotations, c.annotationPrefix+githubRequiredContextsAnnotation),
+		TransientEnvironment:  boolAnnotation(annotations, c.annotationPrefix+githubTransientEnvironmentAnnotation),
+	}
+}
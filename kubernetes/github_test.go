@@ -0,0 +1,135 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGitHubEnvironment(t *testing.T) {
+	deployment := NewDeployment("", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"github-environment": "production",
+			},
+		},
+	})
+
+	client := &Client{}
+
+	expected := "production"
+	if got := client.GitHubAnnotations(deployment).Environment; got != expected {
+		t.Errorf("expected: %q, got: %q", expected, got)
+	}
+}
+
+func TestGitHubProductionEnvironment(t *testing.T) {
+	testcases := []struct {
+		annotations map[string]string
+		expected    *bool
+	}{
+		{
+			annotations: map[string]string{
+				"github-production-environment": "true",
+			},
+			expected: func() *bool { b := true; return &b }(),
+		},
+		{
+			annotations: map[string]string{
+				"github-production-environment": "0",
+			},
+			expected: func() *bool { b := false; return &b }(),
+		},
+		{
+			annotations: map[string]string{},
+			expected:    nil,
+		},
+	}
+
+	client := &Client{}
+
+	for _, tc := range testcases {
+		deployment := NewDeployment("", &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "deployment",
+				Namespace:   "default",
+				Annotations: tc.annotations,
+			},
+		})
+
+		if got := client.GitHubAnnotations(deployment).ProductionEnvironment; !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected: %v, got: %v", tc.expected, got)
+		}
+	}
+}
+
+func TestGitHubRequiredContexts(t *testing.T) {
+	testcases := []struct {
+		annotations map[string]string
+		expected    []string
+	}{
+		{
+			annotations: map[string]string{
+				"github-required-contexts": "ci/circleci: test, lint",
+			},
+			expected: []string{"ci/circleci: test", "lint"},
+		},
+		{
+			annotations: map[string]string{
+				"github-required-contexts": "",
+			},
+			expected: []string{},
+		},
+		{
+			annotations: map[string]string{},
+			expected:    nil,
+		},
+	}
+
+	client := &Client{}
+
+	for _, tc := range testcases {
+		deployment := NewDeployment("", &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "deployment",
+				Namespace:   "default",
+				Annotations: tc.annotations,
+			},
+		})
+
+		if got := client.GitHubAnnotations(deployment).RequiredContexts; !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected: %v, got: %v", tc.expected, got)
+		}
+	}
+}
+
+func TestGitHubAnnotations_annotationPrefix(t *testing.T) {
+	statefulSet := NewStatefulSet("example.com/", &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "statefulset",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"example.com/github-auto-inactive": "false",
+				"example.com/github-environment":   "staging",
+				"github-environment":               "production",
+			},
+		},
+	})
+	client := &Client{
+		annotationPrefix: "example.com/",
+	}
+
+	got := client.GitHubAnnotations(statefulSet)
+
+	if got.Environment != "staging" {
+		t.Errorf("expected: %q, got: %q", "staging", got.Environment)
+	}
+
+	if got.AutoInactive == nil || *got.AutoInactive {
+		t.Errorf("expected: false, got: %v", got.AutoInactive)
+	}
+}
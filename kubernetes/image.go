@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Image represents the reference of Docker image
+// e.g. `localhost:5000/dtan4/app:v1@sha256:...`
+type Image struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImage parses the given image reference
+// Registry is set only if the first path component looks like hostname
+// (contains "." or ":", or is "localhost")
+func ParseImage(image string) (*Image, error) {
+	if image == "" {
+		return nil, errors.New("image is empty")
+	}
+
+	img := &Image{}
+	name := image
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		img.Digest = name[i+1:]
+		name = name[:i]
+
+		if !strings.Contains(img.Digest, ":") {
+			return nil, errors.Errorf("invalid digest %q in image %q", img.Digest, image)
+		}
+	}
+
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i+1:], "/") {
+		img.Tag = name[i+1:]
+		name = name[:i]
+
+		if img.Tag == "" {
+			return nil, errors.Errorf("empty tag in image %q", image)
+		}
+	}
+
+	if i := strings.Index(name, "/"); i >= 0 {
+		host := name[:i]
+
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			img.Registry = host
+			name = name[i+1:]
+		}
+	}
+
+	if name == "" {
+		return nil, errors.Errorf("empty repository in image %q", image)
+	}
+
+	img.Repository = name
+
+	return img, nil
+}
+
+// Name returns the image name without tag and digest
+// e.g. `localhost:5000/dtan4/app`
+func (i *Image) Name() string {
+	if i.Registry == "" {
+		return i.Repository
+	}
+
+	return i.Registry + "/" + i.Repository
+}
+
+// String returns the full image reference
+func (i *Image) String() string {
+	s := i.Name()
+
+	if i.Tag != "" {
+		s += ":" + i.Tag
+	}
+
+	if i.Digest != "" {
+		s += "@" + i.Digest
+	}
+
+	return s
+}
+
+// WithTag returns the image of the same name with the given tag
+// Digest is dropped because it does not match the new tag
+func (i *Image) WithTag(tag string) *Image {
+	return &Image{
+		Registry:   i.Registry,
+		Repository: i.Repository,
+		Tag:        tag,
+	}
+}
@@ -0,0 +1,139 @@
+package kubernetes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseImage(t *testing.T) {
+	testcases := []struct {
+		image     string
+		expectErr bool
+		expected  *Image
+		name      string
+		errMsg    string
+	}{
+		{
+			image: "nginx",
+			expected: &Image{
+				Repository: "nginx",
+			},
+			name: "nginx",
+		},
+		{
+			image: "dtan4/my-rails:v1",
+			expected: &Image{
+				Repository: "dtan4/my-rails",
+				Tag:        "v1",
+			},
+			name: "dtan4/my-rails",
+		},
+		{
+			image: "quay.io/dtan4/k8ship:0118ef0b66a6b9cb04a6547aca5a17d0ad601782",
+			expected: &Image{
+				Registry:   "quay.io",
+				Repository: "dtan4/k8ship",
+				Tag:        "0118ef0b66a6b9cb04a6547aca5a17d0ad601782",
+			},
+			name: "quay.io/dtan4/k8ship",
+		},
+		{
+			image: "localhost:5000/app:sha",
+			expected: &Image{
+				Registry:   "localhost:5000",
+				Repository: "app",
+				Tag:        "sha",
+			},
+			name: "localhost:5000/app",
+		},
+		{
+			image: "localhost:5000/app",
+			expected: &Image{
+				Registry:   "localhost:5000",
+				Repository: "app",
+			},
+			name: "localhost:5000/app",
+		},
+		{
+			image: "registry.example.com/app@sha256:0123456789abcdef",
+			expected: &Image{
+				Registry:   "registry.example.com",
+				Repository: "app",
+				Digest:     "sha256:0123456789abcdef",
+			},
+			name: "registry.example.com/app",
+		},
+		{
+			image: "registry.example.com:443/team/app:v2@sha256:0123456789abcdef",
+			expected: &Image{
+				Registry:   "registry.example.com:443",
+				Repository: "team/app",
+				Tag:        "v2",
+				Digest:     "sha256:0123456789abcdef",
+			},
+			name: "registry.example.com:443/team/app",
+		},
+		{
+			image:     "",
+			expectErr: true,
+			errMsg:    "image is empty",
+		},
+		{
+			image:     "app@0123456789abcdef",
+			expectErr: true,
+			errMsg:    `invalid digest "0123456789abcdef"`,
+		},
+		{
+			image:     "app:",
+			expectErr: true,
+			errMsg:    `empty tag in image "app:"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		got, err := ParseImage(tc.image)
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+				continue
+			}
+
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.errMsg)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+				continue
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected: %#v, got: %#v", tc.expected, got)
+			}
+
+			if got.Name() != tc.name {
+				t.Errorf("expected: %q, got: %q", tc.name, got.Name())
+			}
+
+			if got.String() != tc.image {
+				t.Errorf("expected: %q, got: %q", tc.image, got.String())
+			}
+		}
+	}
+}
+
+func TestImageWithTag(t *testing.T) {
+	img := &Image{
+		Registry:   "localhost:5000",
+		Repository: "app",
+		Tag:        "v1",
+		Digest:     "sha256:0123456789abcdef",
+	}
+
+	expected := "localhost:5000/app:v2"
+	if got := img.WithTag("v2").String(); got != expected {
+		t.Errorf("expected: %q, got: %q", expected, got)
+	}
+}
@@ -1,10 +1,8 @@
 package kubernetes
 
 import (
-	"strings"
-
 	"github.com/pkg/errors"
-	"k8s.io/client-go/pkg/api/v1"
+	"k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -13,8 +11,15 @@ const (
 	deployTargetContainerAnnotation = "deploy-target-container"
 	deployUserAnnotation            = "deploy-user"
 	githubAnnotation                = "github"
+	pinDigestAnnotation             = "pin-digest"
 	reloadedAtAnnotation            = "reloaded-at"
 
+	githubAutoInactiveAnnotation          = "github-auto-inactive"
+	githubEnvironmentAnnotation           = "github-environment"
+	githubProductionEnvironmentAnnotation = "github-production-environment"
+	githubRequiredContextsAnnotation      = "github-required-contexts"
+	githubTransientEnvironmentAnnotation  = "github-transient-environment"
+
 	changeCauseAnnotation = "kubernetes.io/change-cause"
 	revisionAnnotation    = "deployment.kubernetes.io/revision"
 )
@@ -24,17 +29,29 @@ func DefaultConfigFile() string {
 	return clientcmd.RecommendedHomeFile
 }
 
+// AllNamespaces returns the namespace which lists objects across all namespaces
+func AllNamespaces() string {
+	return v1.NamespaceAll
+}
+
 // DefaultNamespace returns the default namespace
 func DefaultNamespace() string {
 	return v1.NamespaceDefault
 }
 
 // GetTargetImage returns the unique image name of target containers
-func GetTargetImage(containers map[string]*Container) (string, error) {
+func GetTargetImage(containers map[string][]*Container) (string, error) {
 	images := map[string]bool{}
 
-	for _, c := range containers {
-		images[strings.Split(c.Image(), ":")[0]] = true
+	for _, cs := range containers {
+		for _, c := range cs {
+			img, err := ParseImage(c.Image())
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to parse image of container %q", c.Name())
+			}
+
+			images[img.Name()] = true
+		}
 	}
 
 	ss := make([]string, 0, len(images))
@@ -54,37 +71,36 @@ func GetTargetImage(containers map[string]*Container) (string, error) {
 	return ss[0], nil
 }
 
-// GetTargetRepository returns the unique GitHub repository of target containers
-func GetTargetRepository(deployments []*Deployment, containers map[string]*Container) (string, error) {
-	repos := map[string]bool{}
+// GroupContainersByRepository groups the target containers of workloads by their GitHub repository
+// containers must be keyed by WorkloadKey, and the result is keyed by repository and then WorkloadKey
+// Containers of one workload may belong to different repositories
+func GroupContainersByRepository(workloads []Workload, containers map[string][]*Container) (map[string]map[string][]*Container, error) {
+	groups := map[string]map[string][]*Container{}
 
-	for _, d := range deployments {
-		c, ok := containers[d.Name()]
-		if !ok {
-			return "", errors.Errorf("no container found in Deployment %q", d.Name())
+	for _, w := range workloads {
+		cs, ok := containers[WorkloadKey(w)]
+		if !ok || len(cs) == 0 {
+			return nil, errors.Errorf("no container found in %s %q", w.Kind(), w.Name())
 		}
 
-		rs, err := d.Repositories()
+		rs, err := w.Repositories()
 		if err != nil {
-			return "", errors.Wrapf(err, "failed to retrieve repositories of Deployment %q", d.Name())
+			return nil, errors.Wrapf(err, "failed to retrieve repositories of %s %q", w.Kind(), w.Name())
 		}
 
-		v, ok := rs[c.Name()]
-		if !ok {
-			return "", errors.Errorf("GitHub repository for container %q in Deployment %q is not set", c.Name(), d.Name())
-		}
-		repos[v] = true
-	}
-
-	ss := make([]string, 0, len(repos))
+		for _, c := range cs {
+			repo, ok := rs[c.Name()]
+			if !ok {
+				return nil, errors.Errorf("GitHub repository for container %q in %s %q is not set", c.Name(), w.Kind(), w.Name())
+			}
 
-	for k := range repos {
-		ss = append(ss, k)
-	}
+			if _, ok := groups[repo]; !ok {
+				groups[repo] = map[string][]*Container{}
+			}
 
-	if len(repos) > 1 {
-		return "", errors.Errorf("multiple repositories %q found, all target containers must use the same repository", ss)
+			groups[repo][WorkloadKey(w)] = append(groups[repo][WorkloadKey(w)], c)
+		}
 	}
 
-	return ss[0], nil
+	return groups, nil
 }
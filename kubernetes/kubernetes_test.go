@@ -1,26 +1,30 @@
 package kubernetes
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
-	"k8s.io/client-go/pkg/api/v1"
-	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGetTargetImage(t *testing.T) {
 	testcases := []struct {
-		containers map[string]*Container
+		containers map[string][]*Container
 		expectErr  bool
 		expected   string
 		errMsg     string
 	}{
 		{
-			containers: map[string]*Container{
-				"web": &Container{
-					raw: &v1.Container{
-						Name:  "web",
-						Image: "my-rails:v3",
+			containers: map[string][]*Container{
+				"web": []*Container{
+					&Container{
+						raw: &v1.Container{
+							Name:  "web",
+							Image: "my-rails:v3",
+						},
 					},
 				},
 			},
@@ -28,17 +32,21 @@ func TestGetTargetImage(t *testing.T) {
 			expected:  "my-rails",
 		},
 		{
-			containers: map[string]*Container{
-				"web": &Container{
-					raw: &v1.Container{
-						Name:  "web",
-						Image: "my-rails:v3",
+			containers: map[string][]*Container{
+				"web": []*Container{
+					&Container{
+						raw: &v1.Container{
+							Name:  "web",
+							Image: "my-rails:v3",
+						},
 					},
 				},
-				"worker": &Container{
-					raw: &v1.Container{
-						Name:  "worker",
-						Image: "my-rails:v3",
+				"worker": []*Container{
+					&Container{
+						raw: &v1.Container{
+							Name:  "worker",
+							Image: "my-rails:v3",
+						},
 					},
 				},
 			},
@@ -46,17 +54,21 @@ func TestGetTargetImage(t *testing.T) {
 			expected:  "my-rails",
 		},
 		{
-			containers: map[string]*Container{
-				"web": &Container{
-					raw: &v1.Container{
-						Name:  "web",
-						Image: "my-rails:v3",
+			containers: map[string][]*Container{
+				"web": []*Container{
+					&Container{
+						raw: &v1.Container{
+							Name:  "web",
+							Image: "my-rails:v3",
+						},
 					},
 				},
-				"worker": &Container{
-					raw: &v1.Container{
-						Name:  "worker",
-						Image: "my-rails:abc123",
+				"worker": []*Container{
+					&Container{
+						raw: &v1.Container{
+							Name:  "worker",
+							Image: "my-rails:abc123",
+						},
 					},
 				},
 			},
@@ -64,17 +76,21 @@ func TestGetTargetImage(t *testing.T) {
 			expected:  "my-rails",
 		},
 		{
-			containers: map[string]*Container{
-				"web": &Container{
-					raw: &v1.Container{
-						Name:  "web",
-						Image: "my-rails:v3",
+			containers: map[string][]*Container{
+				"web": []*Container{
+					&Container{
+						raw: &v1.Container{
+							Name:  "web",
+							Image: "my-rails:v3",
+						},
 					},
 				},
-				"nginx": &Container{
-					raw: &v1.Container{
-						Name:  "nginx",
-						Image: "nginx:latest",
+				"nginx": []*Container{
+					&Container{
+						raw: &v1.Container{
+							Name:  "nginx",
+							Image: "nginx:latest",
+						},
 					},
 				},
 			},
@@ -84,7 +100,29 @@ func TestGetTargetImage(t *testing.T) {
 			errMsg: `all target containers must use the same image`,
 		},
 		{
-			containers: map[string]*Container{},
+			containers: map[string][]*Container{
+				"Deployment/web": []*Container{
+					&Container{
+						raw: &v1.Container{
+							Name:  "web",
+							Image: "localhost:5000/my-rails:v3",
+						},
+					},
+				},
+				"CronJob/batch": []*Container{
+					&Container{
+						raw: &v1.Container{
+							Name:  "batch",
+							Image: "localhost:5000/my-rails:abc123",
+						},
+					},
+				},
+			},
+			expectErr: false,
+			expected:  "localhost:5000/my-rails",
+		},
+		{
+			containers: map[string][]*Container{},
 			expectErr:  true,
 			errMsg:     `no image found`,
 		},
@@ -114,195 +152,109 @@ func TestGetTargetImage(t *testing.T) {
 	}
 }
 
-func TestGetTargetRepository(t *testing.T) {
+func TestGroupContainersByRepository(t *testing.T) {
+	web := &Container{
+		raw: &v1.Container{
+			Name:  "web",
+			Image: "my-rails:v3",
+		},
+	}
+	worker := &Container{
+		raw: &v1.Container{
+			Name:  "worker",
+			Image: "my-worker:v3",
+		},
+	}
+	api := &Container{
+		raw: &v1.Container{
+			Name:  "api",
+			Image: "my-api:v1",
+		},
+	}
+
 	testcases := []struct {
-		deployments []*Deployment
-		containers  map[string]*Container
-		expectErr   bool
-		expected    string
-		errMsg      string
+		workloads  []Workload
+		containers map[string][]*Container
+		expectErr  bool
+		expected   map[string]map[string][]*Container
+		errMsg     string
 	}{
 		{
-			deployments: []*Deployment{
-				&Deployment{
-					raw: &v1beta1.Deployment{
-						ObjectMeta: v1.ObjectMeta{
-							Name:      "web",
-							Namespace: "default",
-							Annotations: map[string]string{
-								"github": "web=dtan4/my-rails",
-							},
-						},
-					},
-				},
-			},
-			containers: map[string]*Container{
-				"web": &Container{
-					raw: &v1.Container{
-						Name:  "web",
-						Image: "my-rails:v3",
-					},
-				},
-			},
-			expectErr: false,
-			expected:  "dtan4/my-rails",
-		},
-		{
-			deployments: []*Deployment{
-				&Deployment{
-					raw: &v1beta1.Deployment{
-						ObjectMeta: v1.ObjectMeta{
-							Name:      "web",
-							Namespace: "default",
-							Annotations: map[string]string{
-								"github": "web=dtan4/my-rails",
-							},
+			workloads: []Workload{
+				NewDeployment("", &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "web",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"github": "web=dtan4/my-rails,worker=dtan4/my-worker",
 						},
 					},
-				},
-				&Deployment{
-					raw: &v1beta1.Deployment{
-						ObjectMeta: v1.ObjectMeta{
-							Name:      "worker",
-							Namespace: "default",
-							Annotations: map[string]string{
-								"github": "worker=dtan4/my-rails",
-							},
+				}),
+				NewDeployment("", &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "api",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"github": "api=dtan4/my-api",
 						},
 					},
-				},
+				}),
 			},
-			containers: map[string]*Container{
-				"web": &Container{
-					raw: &v1.Container{
-						Name:  "web",
-						Image: "my-rails:v3",
-					},
-				},
-				"worker": &Container{
-					raw: &v1.Container{
-						Name:  "worker",
-						Image: "my-rails:v3",
-					},
-				},
+			containers: map[string][]*Container{
+				"Deployment/web": []*Container{web, worker},
+				"Deployment/api": []*Container{api},
 			},
 			expectErr: false,
-			expected:  "dtan4/my-rails",
-		},
-		{
-			deployments: []*Deployment{
-				&Deployment{
-					raw: &v1beta1.Deployment{
-						ObjectMeta: v1.ObjectMeta{
-							Name:        "web",
-							Namespace:   "default",
-							Annotations: map[string]string{},
-						},
-					},
+			expected: map[string]map[string][]*Container{
+				"dtan4/my-rails": map[string][]*Container{
+					"Deployment/web": []*Container{web},
 				},
-			},
-			containers: map[string]*Container{
-				"web": &Container{
-					raw: &v1.Container{
-						Name:  "web",
-						Image: "my-rails:v3",
-					},
-				},
-			},
-			expectErr: true,
-			errMsg:    `failed to retrieve repositories of Deployment "web"`,
-		},
-		{
-			deployments: []*Deployment{
-				&Deployment{
-					raw: &v1beta1.Deployment{
-						ObjectMeta: v1.ObjectMeta{
-							Name:      "web",
-							Namespace: "default",
-							Annotations: map[string]string{
-								"github": "foo=dtan4/foo",
-							},
-						},
-					},
+				"dtan4/my-worker": map[string][]*Container{
+					"Deployment/web": []*Container{worker},
 				},
-			},
-			containers: map[string]*Container{
-				"web": &Container{
-					raw: &v1.Container{
-						Name:  "web",
-						Image: "my-rails:v3",
-					},
+				"dtan4/my-api": map[string][]*Container{
+					"Deployment/api": []*Container{api},
 				},
 			},
-			expectErr: true,
-			errMsg:    `GitHub repository for container "web" in Deployment "web" is not set`,
 		},
 		{
-			deployments: []*Deployment{
-				&Deployment{
-					raw: &v1beta1.Deployment{
-						ObjectMeta: v1.ObjectMeta{
-							Name:      "web",
-							Namespace: "default",
-							Annotations: map[string]string{
-								"github": "web=dtan4/my-rails",
-							},
+			workloads: []Workload{
+				NewDeployment("", &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "web",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"github": "web=dtan4/my-rails",
 						},
 					},
-				},
-				&Deployment{
-					raw: &v1beta1.Deployment{
-						ObjectMeta: v1.ObjectMeta{
-							Name:      "nginx",
-							Namespace: "default",
-							Annotations: map[string]string{
-								"github": "nginx=dtan4/my-nginx",
-							},
-						},
-					},
-				},
+				}),
 			},
-			containers: map[string]*Container{
-				"web": &Container{
-					raw: &v1.Container{
-						Name:  "web",
-						Image: "my-rails:v3",
-					},
-				},
-				"nginx": &Container{
-					raw: &v1.Container{
-						Name:  "nginx",
-						Image: "nginx:latest",
-					},
-				},
+			containers: map[string][]*Container{
+				"Deployment/web": []*Container{web, worker},
 			},
 			expectErr: true,
-			// order of repository list is random
-			// because this list is extracted from map[string]bool
-			errMsg: `all target containers must use the same repository`,
+			errMsg:    `GitHub repository for container "worker" in Deployment "web" is not set`,
 		},
 		{
-			deployments: []*Deployment{
-				&Deployment{
-					raw: &v1beta1.Deployment{
-						ObjectMeta: v1.ObjectMeta{
-							Name:      "deployment",
-							Namespace: "default",
-							Annotations: map[string]string{
-								"github": "rails=rails/rails",
-							},
+			workloads: []Workload{
+				NewDeployment("", &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "web",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"github": "web=dtan4/my-rails",
 						},
 					},
-				},
+				}),
 			},
-			containers: map[string]*Container{},
+			containers: map[string][]*Container{},
 			expectErr:  true,
-			errMsg:     `no container found in Deployment "deployment"`,
+			errMsg:     `no container found in Deployment "web"`,
 		},
 	}
 
 	for _, tc := range testcases {
-		got, err := GetTargetRepository(tc.deployments, tc.containers)
+		got, err := GroupContainersByRepository(tc.workloads, tc.containers)
 
 		if tc.expectErr {
 			if err == nil {
@@ -316,10 +268,11 @@ func TestGetTargetRepository(t *testing.T) {
 		} else {
 			if err != nil {
 				t.Errorf("got error: %s", err)
+				continue
 			}
 
-			if got != tc.expected {
-				t.Errorf("expected: %q, got: %q", tc.expected, got)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected: %v, got: %v", tc.expected, got)
 			}
 		}
 	}
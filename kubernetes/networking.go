@@ -0,0 +1,111 @@
+package kubernetes
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ingressesResource        = "ingresses"
+	networkingV1GroupVersion = "networking.k8s.io/v1"
+)
+
+// ingressV1 represents the fields of networking.k8s.io/v1 Ingress used to detect environment URL
+// client-go does not have the typed client of networking.k8s.io/v1 Ingress
+type ingressV1 struct {
+	Spec struct {
+		DefaultBackend *ingressV1Backend `json:"defaultBackend"`
+		Rules          []struct {
+			Host string `json:"host"`
+			HTTP *struct {
+				Paths []struct {
+					Backend ingressV1Backend `json:"backend"`
+				} `json:"paths"`
+			} `json:"http"`
+		} `json:"rules"`
+		TLS []struct {
+			Hosts []string `json:"hosts"`
+		} `json:"tls"`
+	} `json:"spec"`
+}
+
+type ingressV1Backend struct {
+	Service *struct {
+		Name string `json:"name"`
+	} `json:"service"`
+}
+
+type ingressV1List struct {
+	Items []ingressV1 `json:"items"`
+}
+
+// listIngresses lists Ingresses in networking.k8s.io/v1 if served, otherwise in extensions/v1beta1
+// Ingresses in networking.k8s.io/v1 are converted to extensions/v1beta1 objects with the fields used to detect environment URL
+func (c *Client) listIngresses(namespace string) ([]v1beta1.Ingress, error) {
+	if !c.ingressesV1 {
+		ings, err := c.clientset.ExtensionsV1beta1().Ingresses(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		return ings.Items, nil
+	}
+
+	b, err := c.networkingV1.Get().Namespace(namespace).Resource(ingressesResource).DoRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	var list ingressV1List
+
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, errors.Wrap(err, "failed to decode Ingresses")
+	}
+
+	ingresses := make([]v1beta1.Ingress, 0, len(list.Items))
+
+	for _, item := range list.Items {
+		var ing v1beta1.Ingress
+
+		ing.Spec.Backend = item.Spec.DefaultBackend.convert()
+
+		for _, tls := range item.Spec.TLS {
+			ing.Spec.TLS = append(ing.Spec.TLS, v1beta1.IngressTLS{Hosts: tls.Hosts})
+		}
+
+		for _, r := range item.Spec.Rules {
+			rule := v1beta1.IngressRule{Host: r.Host}
+
+			if r.HTTP != nil {
+				rule.HTTP = &v1beta1.HTTPIngressRuleValue{}
+
+				for _, p := range r.HTTP.Paths {
+					if backend := p.Backend.convert(); backend != nil {
+						rule.HTTP.Paths = append(rule.HTTP.Paths, v1beta1.HTTPIngressPath{Backend: *backend})
+					}
+				}
+			}
+
+			ing.Spec.Rules = append(ing.Spec.Rules, rule)
+		}
+
+		ingresses = append(ingresses, ing)
+	}
+
+	return ingresses, nil
+}
+
+// convert returns the extensions/v1beta1 backend of Service
+// nil is returned if the backend is not Service (e.g. resource backend)
+func (b *ingressV1Backend) convert() *v1beta1.IngressBackend {
+	if b == nil || b.Service == nil {
+		return nil
+	}
+
+	return &v1beta1.IngressBackend{
+		ServiceName: b.Service.Name,
+	}
+}
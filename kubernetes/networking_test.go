@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnvironmentURL_networkingV1(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{
+				"app": "web",
+			},
+		},
+	})
+	client := &Client{
+		clientset:   clientset,
+		ingressesV1: true,
+		networkingV1: newFakeRESTClient(schema.GroupVersion{Group: "networking.k8s.io", Version: "v1"}, func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/apis/networking.k8s.io/v1/namespaces/default/ingresses" {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(&bytes.Buffer{})}, nil
+			}
+
+			return jsonResponse(`{
+  "apiVersion": "networking.k8s.io/v1",
+  "kind": "IngressList",
+  "items": [
+    {
+      "metadata": {"name": "api", "namespace": "default"},
+      "spec": {
+        "rules": [
+          {"host": "api.example.com", "http": {"paths": [{"path": "/", "pathType": "Prefix", "backend": {"service": {"name": "api", "port": {"number": 80}}}}]}}
+        ]
+      }
+    },
+    {
+      "metadata": {"name": "web", "namespace": "default"},
+      "spec": {
+        "tls": [{"hosts": ["www.example.com"]}],
+        "rules": [
+          {"host": "www.example.com", "http": {"paths": [{"path": "/", "pathType": "Prefix", "backend": {"service": {"name": "web", "port": {"name": "http"}}}}]}}
+        ]
+      }
+    }
+  ]
+}`), nil
+		}),
+	}
+
+	deployment := NewDeployment("", &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "web",
+					},
+				},
+			},
+		},
+	})
+
+	got, err := client.EnvironmentURL(deployment)
+	if err != nil {
+		t.Errorf("got error: %s", err)
+		return
+	}
+
+	expected := "https://www.example.com"
+	if got != expected {
+		t.Errorf("expected: %q, got: %q", expected, got)
+	}
+}
@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"k8s.io/api/core/v1"
+)
+
+var (
+	podFailureReasons = []string{"CrashLoopBackOff", "ImagePullBackOff"}
+)
+
+// Pod represents the wrapper of Kubernetes Pod
+type Pod struct {
+	raw *v1.Pod
+}
+
+// NewPod creates new Pod object
+func NewPod(raw *v1.Pod) *Pod {
+	return &Pod{
+		raw: raw,
+	}
+}
+
+// FailedContainers returns the containers which cannot start, with their waiting reason
+// - waiting with `CrashLoopBackOff` or `ImagePullBackOff`
+func (p *Pod) FailedContainers() map[string]string {
+	containers := map[string]string{}
+
+	for _, s := range p.raw.Status.ContainerStatuses {
+		if s.State.Waiting == nil {
+			continue
+		}
+
+		for _, r := range podFailureReasons {
+			if s.State.Waiting.Reason == r {
+				containers[s.Name] = r
+			}
+		}
+	}
+
+	return containers
+}
+
+// Labels returns the labels of Pod
+func (p *Pod) Labels() map[string]string {
+	return p.raw.Labels
+}
+
+// Name returns the name of Pod
+func (p *Pod) Name() string {
+	return p.raw.Name
+}
+
+// Namespace returns the namespace of Pod
+func (p *Pod) Namespace() string {
+	return p.raw.Namespace
+}
@@ -0,0 +1,72 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFailedContainers(t *testing.T) {
+	raw := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment-1234567890-abcde",
+			Namespace: "default",
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				v1.ContainerStatus{
+					Name: "web",
+					State: v1.ContainerState{
+						Waiting: &v1.ContainerStateWaiting{
+							Reason: "CrashLoopBackOff",
+						},
+					},
+				},
+				v1.ContainerStatus{
+					Name: "worker",
+					State: v1.ContainerState{
+						Waiting: &v1.ContainerStateWaiting{
+							Reason: "ContainerCreating",
+						},
+					},
+				},
+				v1.ContainerStatus{
+					Name: "nginx",
+					State: v1.ContainerState{
+						Running: &v1.ContainerStateRunning{},
+					},
+				},
+			},
+		},
+	}
+	p := &Pod{
+		raw: raw,
+	}
+
+	got := p.FailedContainers()
+	want := map[string]string{
+		"web": "CrashLoopBackOff",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %#v, got: %#v", want, got)
+	}
+}
+
+func TestPodName(t *testing.T) {
+	raw := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment-1234567890-abcde",
+			Namespace: "default",
+		},
+	}
+	p := &Pod{
+		raw: raw,
+	}
+
+	want := "deployment-1234567890-abcde"
+	if got := p.Name(); got != want {
+		t.Errorf("want: %q, got: %q", want, got)
+	}
+}
@@ -1,19 +1,21 @@
 package kubernetes
 
 import (
+	"strconv"
 	"time"
 
-	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
 )
 
 // ReplicaSet represents the wrapper of Kubernetes ReplicaSet
 type ReplicaSet struct {
 	annotationPrefix string
-	raw              *v1beta1.ReplicaSet
+	raw              *appsv1.ReplicaSet
 }
 
 // NewReplicaSet creates ne ReplicaSet object
-func NewReplicaSet(annotationPrefix string, raw *v1beta1.ReplicaSet) *ReplicaSet {
+func NewReplicaSet(annotationPrefix string, raw *appsv1.ReplicaSet) *ReplicaSet {
 	return &ReplicaSet{
 		annotationPrefix: annotationPrefix,
 		raw:              raw,
@@ -55,3 +57,18 @@ func (r *ReplicaSet) Namespace() string {
 func (r *ReplicaSet) Revision() string {
 	return r.raw.Annotations[revisionAnnotation]
 }
+
+// RevisionNumber returns the revision as number
+func (r *ReplicaSet) RevisionNumber() (int64, error) {
+	n, err := strconv.ParseInt(r.Revision(), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid revision %q in ReplicaSet %q", r.Revision(), r.Name())
+	}
+
+	return n, nil
+}
+
+// UID returns the UID of ReplicaSet
+func (r *ReplicaSet) UID() string {
+	return string(r.raw.UID)
+}
@@ -5,17 +5,17 @@ import (
 	"testing"
 	"time"
 
-	"k8s.io/client-go/pkg/api/unversioned"
-	"k8s.io/client-go/pkg/api/v1"
-	"k8s.io/client-go/pkg/apis/extensions/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestCreatedAt(t *testing.T) {
-	raw := &v1beta1.ReplicaSet{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment-1234567890",
 			Namespace: "default",
-			CreationTimestamp: unversioned.Time{
+			CreationTimestamp: metav1.Time{
 				Time: time.Date(2017, 12, 14, 16, 36, 17, 0, time.UTC),
 			},
 		},
@@ -32,17 +32,17 @@ func TestCreatedAt(t *testing.T) {
 }
 
 func TestDeployUser(t *testing.T) {
-	raw := &v1beta1.ReplicaSet{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment-1234567890",
 			Namespace: "default",
-			CreationTimestamp: unversioned.Time{
+			CreationTimestamp: metav1.Time{
 				Time: time.Date(2017, 12, 14, 16, 36, 17, 0, time.UTC),
 			},
 		},
-		Spec: v1beta1.ReplicaSetSpec{
+		Spec: appsv1.ReplicaSetSpec{
 			Template: v1.PodTemplateSpec{
-				ObjectMeta: v1.ObjectMeta{
+				ObjectMeta: metav1.ObjectMeta{
 					Annotations: map[string]string{
 						"deploy-user": "dtan4",
 					},
@@ -62,12 +62,12 @@ func TestDeployUser(t *testing.T) {
 }
 
 func TestImages(t *testing.T) {
-	raw := &v1beta1.ReplicaSet{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
 			Name:      "deployment-1234567890",
 			Namespace: "default",
 		},
-		Spec: v1beta1.ReplicaSetSpec{
+		Spec: appsv1.ReplicaSetSpec{
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
@@ -99,8 +99,8 @@ func TestImages(t *testing.T) {
 }
 
 func TestRevision(t *testing.T) {
-	raw := &v1beta1.ReplicaSet{
-		ObjectMeta: v1.ObjectMeta{
+	raw := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
 			Annotations: map[string]string{
 				"deployment.kubernetes.io/revision": "1",
 			},
@@ -118,3 +118,52 @@ func TestRevision(t *testing.T) {
 		t.Errorf("want: %q, got: %q", want, got)
 	}
 }
+
+func TestRevisionNumber(t *testing.T) {
+	testcases := []struct {
+		revision  string
+		expectErr bool
+		expected  int64
+	}{
+		{
+			revision:  "12",
+			expectErr: false,
+			expected:  12,
+		},
+		{
+			revision:  "",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		r := &ReplicaSet{
+			raw: &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"deployment.kubernetes.io/revision": tc.revision,
+					},
+					Name:      "deployment-1234567890",
+					Namespace: "default",
+				},
+			},
+		}
+
+		got, err := r.RevisionNumber()
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+				continue
+			}
+
+			if got != tc.expected {
+				t.Errorf("want: %d, got: %d", tc.expected, got)
+			}
+		}
+	}
+}
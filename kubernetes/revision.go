@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Revision represents the revision of Workload
+// ReplicaSet for Deployment, ControllerRevision for StatefulSet and DaemonSet
+type Revision interface {
+	CreatedAt() time.Time
+	DeployUser() string
+	Images() map[string]string
+	Name() string
+	Revision() string
+	RevisionNumber() (int64, error)
+}
+
+// FindRollbackRevision returns the Revision of the given revision number
+// If revision is 0, the previous Revision is returned
+func FindRollbackRevision(rs []Revision, revision int64) (Revision, error) {
+	revisions := make([]Revision, 0, len(rs))
+
+	for _, r := range rs {
+		if _, err := r.RevisionNumber(); err != nil {
+			continue
+		}
+
+		revisions = append(revisions, r)
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		ri, _ := revisions[i].RevisionNumber()
+		rj, _ := revisions[j].RevisionNumber()
+
+		return ri > rj
+	})
+
+	if revision == 0 {
+		if len(revisions) < 2 {
+			return nil, errors.New("no previous revision found")
+		}
+
+		return revisions[1], nil
+	}
+
+	for _, r := range revisions {
+		if n, _ := r.RevisionNumber(); n == revision {
+			return r, nil
+		}
+	}
+
+	return nil, errors.Errorf("revision %d not found", revision)
+}
@@ -0,0 +1,104 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindRollbackRevision(t *testing.T) {
+	rs := []Revision{
+		&ReplicaSet{
+			raw: &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"deployment.kubernetes.io/revision": "2",
+					},
+					Name:      "deployment-2222222222",
+					Namespace: "default",
+				},
+			},
+		},
+		&ReplicaSet{
+			raw: &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"deployment.kubernetes.io/revision": "10",
+					},
+					Name:      "deployment-1010101010",
+					Namespace: "default",
+				},
+			},
+		},
+		&ReplicaSet{
+			raw: &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"deployment.kubernetes.io/revision": "9",
+					},
+					Name:      "deployment-9999999999",
+					Namespace: "default",
+				},
+			},
+		},
+	}
+
+	testcases := []struct {
+		rs        []Revision
+		revision  int64
+		expectErr bool
+		expected  string
+		errMsg    string
+	}{
+		{
+			rs:        rs,
+			revision:  0,
+			expectErr: false,
+			expected:  "deployment-9999999999",
+		},
+		{
+			rs:        rs,
+			revision:  2,
+			expectErr: false,
+			expected:  "deployment-2222222222",
+		},
+		{
+			rs:        rs,
+			revision:  3,
+			expectErr: true,
+			errMsg:    "revision 3 not found",
+		},
+		{
+			rs:        rs[0:1],
+			revision:  0,
+			expectErr: true,
+			errMsg:    "no previous revision found",
+		},
+	}
+
+	for _, tc := range testcases {
+		got, err := FindRollbackRevision(tc.rs, tc.revision)
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+				continue
+			}
+
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.errMsg)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+				continue
+			}
+
+			if got.Name() != tc.expected {
+				t.Errorf("want: %q, got: %q", tc.expected, got.Name())
+			}
+		}
+	}
+}
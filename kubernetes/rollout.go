@@ -0,0 +1,234 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	progressDeadlineExceededReason = "ProgressDeadlineExceeded"
+	rolloutTimeoutReason           = "Timeout"
+
+	controllerRevisionHashLabel = "controller-revision-hash"
+)
+
+var (
+	rolloutPollInterval = 2 * time.Second
+)
+
+// RolloutError represents the failure of Workload rollout
+type RolloutError struct {
+	Kind    string
+	Name    string
+	Reason  string
+	Message string
+}
+
+func (e *RolloutError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("rollout of %s %q failed: %s", e.Kind, e.Name, e.Reason)
+	}
+
+	return fmt.Sprintf("rollout of %s %q failed: %s: %s", e.Kind, e.Name, e.Reason, e.Message)
+}
+
+// WaitForRollout blocks until the rollout of the given workload succeeds, fails or times out
+// progress is called with the progress message every time it changes
+func (c *Client) WaitForRollout(workload Workload, timeout time.Duration, progress func(string)) (Workload, error) {
+	deadline := time.Now().Add(timeout)
+	lastMessage := ""
+
+	for {
+		w, err := c.getWorkload(workload.Namespace(), workload.Kind(), workload.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve rollout status of %s %q", workload.Kind(), workload.Name())
+		}
+
+		done, message, err := w.RolloutStatus()
+		if err != nil {
+			return w, err
+		}
+
+		if !done && rolloutStarted(workload, w) {
+			if err := c.detectPodFailure(w); err != nil {
+				return w, err
+			}
+		}
+
+		if progress != nil && message != lastMessage {
+			progress(message)
+			lastMessage = message
+		}
+
+		if done {
+			return w, nil
+		}
+
+		if time.Now().After(deadline) {
+			return w, &RolloutError{
+				Kind:    w.Kind(),
+				Name:    w.Name(),
+				Reason:  rolloutTimeoutReason,
+				Message: fmt.Sprintf("rollout did not finish in %s: %s", timeout, message),
+			}
+		}
+
+		time.Sleep(rolloutPollInterval)
+	}
+}
+
+// rolloutStarted returns whether the controller has started the rollout of the updated workload
+// Until the controller observes the new generation and advances the revision,
+// the latest revision still points to the Pods before update, which may have been failing already.
+// If the updated workload had been observed already (e.g. its Pod template was not changed), the rollout has started
+func rolloutStarted(updated, current Workload) bool {
+	switch c := current.(type) {
+	case *Deployment:
+		u, ok := updated.(*Deployment)
+		if !ok || c.raw.Generation > c.raw.Status.ObservedGeneration {
+			return false
+		}
+
+		return u.raw.Generation <= u.raw.Status.ObservedGeneration || c.Revision() != u.Revision()
+	case *StatefulSet:
+		u, ok := updated.(*StatefulSet)
+		if !ok || c.raw.Generation > c.raw.Status.ObservedGeneration {
+			return false
+		}
+
+		return u.raw.Generation <= u.raw.Status.ObservedGeneration || c.UpdateRevision() != u.UpdateRevision()
+	case *DaemonSet:
+		// DaemonSet controller creates the ControllerRevision of the new generation before it updates status
+		return c.raw.Generation <= c.raw.Status.ObservedGeneration
+	}
+
+	return true
+}
+
+// detectPodFailure returns RolloutError if any Pod of the latest revision cannot start
+func (c *Client) detectPodFailure(workload Workload) error {
+	pods, err := c.listRolloutPods(workload)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pods {
+		for container, reason := range p.FailedContainers() {
+			return &RolloutError{
+				Kind:    workload.Kind(),
+				Name:    workload.Name(),
+				Reason:  reason,
+				Message: fmt.Sprintf("container %q in Pod %q is in %s", container, p.Name(), reason),
+			}
+		}
+	}
+
+	return nil
+}
+
+// listRolloutPods returns the Pods of the latest revision in the given workload
+// - Deployment: Pods of ReplicaSet in the current revision
+// - StatefulSet: Pods labeled with the update revision
+// - DaemonSet: all Pods owned by DaemonSet
+func (c *Client) listRolloutPods(workload Workload) ([]*Pod, error) {
+	switch w := workload.(type) {
+	case *Deployment:
+		rs, err := c.ListReplicaSets(w)
+		if err != nil {
+			return []*Pod{}, errors.Wrapf(err, "failed to retrieve ReplicaSets of Deployment %q", w.Name())
+		}
+
+		pods := []*Pod{}
+
+		for _, r := range rs {
+			if r.Revision() != w.Revision() {
+				continue
+			}
+
+			ps, err := c.ListPods(r)
+			if err != nil {
+				return []*Pod{}, errors.Wrapf(err, "failed to retrieve Pods of ReplicaSet %q", r.Name())
+			}
+
+			pods = append(pods, ps...)
+		}
+
+		return pods, nil
+	case *StatefulSet:
+		all, err := c.listOwnedPods(w.Namespace(), w.UID())
+		if err != nil {
+			return []*Pod{}, errors.Wrapf(err, "failed to retrieve Pods of StatefulSet %q", w.Name())
+		}
+
+		pods := []*Pod{}
+
+		for _, p := range all {
+			if p.Labels()[controllerRevisionHashLabel] == w.UpdateRevision() {
+				pods = append(pods, p)
+			}
+		}
+
+		return pods, nil
+	case *DaemonSet:
+		hash, err := c.latestRevisionHash(w)
+		if err != nil {
+			return []*Pod{}, errors.Wrapf(err, "failed to retrieve the latest revision of DaemonSet %q", w.Name())
+		}
+
+		all, err := c.listOwnedPods(w.Namespace(), w.UID())
+		if err != nil {
+			return []*Pod{}, errors.Wrapf(err, "failed to retrieve Pods of DaemonSet %q", w.Name())
+		}
+
+		if hash == "" {
+			return all, nil
+		}
+
+		pods := []*Pod{}
+
+		for _, p := range all {
+			if p.Labels()[controllerRevisionHashLabel] == hash {
+				pods = append(pods, p)
+			}
+		}
+
+		return pods, nil
+	}
+
+	pods, err := c.listOwnedPods(workload.Namespace(), workload.UID())
+	if err != nil {
+		return []*Pod{}, errors.Wrapf(err, "failed to retrieve Pods of %s %q", workload.Kind(), workload.Name())
+	}
+
+	return pods, nil
+}
+
+// latestRevisionHash returns the hash of the latest ControllerRevision of the given workload
+// Empty string is returned if no ControllerRevision is found
+func (c *Client) latestRevisionHash(workload Workload) (string, error) {
+	revisions, err := c.ListRevisions(workload)
+	if err != nil {
+		return "", err
+	}
+
+	var latest *ControllerRevision
+
+	for _, r := range revisions {
+		cr, ok := r.(*ControllerRevision)
+		if !ok {
+			continue
+		}
+
+		if latest == nil || cr.raw.Revision > latest.raw.Revision {
+			latest = cr
+		}
+	}
+
+	if latest == nil {
+		return "", nil
+	}
+
+	return latest.Hash(), nil
+}
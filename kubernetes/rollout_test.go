@@ -0,0 +1,326 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWaitForRollout(t *testing.T) {
+	rolloutPollInterval = 10 * time.Millisecond
+
+	replicas := int32(2)
+
+	testcases := []struct {
+		status    appsv1.DeploymentStatus
+		expectErr bool
+		errMsg    string
+	}{
+		{
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				Replicas:           2,
+				UpdatedReplicas:    2,
+				AvailableReplicas:  2,
+			},
+			expectErr: false,
+		},
+		{
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				Replicas:           2,
+				UpdatedReplicas:    2,
+				AvailableReplicas:  1,
+			},
+			expectErr: true,
+			errMsg:    `rollout of Deployment "deployment" failed: Timeout`,
+		},
+	}
+
+	for _, tc := range testcases {
+		raw := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "deployment",
+				Namespace:  "default",
+				Generation: 1,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+			},
+			Status: tc.status,
+		}
+		deployment := NewDeployment("", raw)
+
+		clientset := fake.NewSimpleClientset(raw)
+		client := &Client{
+			clientset: clientset,
+		}
+
+		_, err := client.WaitForRollout(deployment, 50*time.Millisecond, nil)
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+				continue
+			}
+
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.errMsg)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+			}
+		}
+	}
+}
+
+func TestWaitForRollout_pod_failure(t *testing.T) {
+	rolloutPollInterval = 10 * time.Millisecond
+
+	replicas := int32(2)
+
+	raw := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"deployment.kubernetes.io/revision": "2",
+			},
+			Name:       "deployment",
+			Namespace:  "default",
+			Generation: 1,
+			UID:        (types.UID)("0001"),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Replicas:           3,
+			UpdatedReplicas:    1,
+			AvailableReplicas:  2,
+		},
+	}
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"deployment.kubernetes.io/revision": "2",
+			},
+			Name:      "deployment-1234567890",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				metav1.OwnerReference{
+					UID: (types.UID)("0001"),
+				},
+			},
+			UID: (types.UID)("0002"),
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment-1234567890-abcde",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				metav1.OwnerReference{
+					UID: (types.UID)("0002"),
+				},
+			},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				v1.ContainerStatus{
+					Name: "web",
+					State: v1.ContainerState{
+						Waiting: &v1.ContainerStateWaiting{
+							Reason: "ImagePullBackOff",
+						},
+					},
+				},
+			},
+		},
+	}
+	deployment := NewDeployment("", raw)
+
+	clientset := fake.NewSimpleClientset(raw, replicaSet, pod)
+	client := &Client{
+		clientset: clientset,
+	}
+
+	_, err := client.WaitForRollout(deployment, time.Second, nil)
+	if err == nil {
+		t.Errorf("got no error")
+		return
+	}
+
+	rerr, ok := err.(*RolloutError)
+	if !ok {
+		t.Errorf("error %q is not RolloutError", err)
+		return
+	}
+
+	want := "ImagePullBackOff"
+	if rerr.Reason != want {
+		t.Errorf("want: %q, got: %q", want, rerr.Reason)
+	}
+}
+
+func TestListRolloutPods_daemonSet(t *testing.T) {
+	raw := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "agent",
+			Namespace: "default",
+			UID:       (types.UID)("0001"),
+		},
+	}
+	revisions := []*appsv1.ControllerRevision{}
+	for hash, revision := range map[string]int64{"1111111111": 1, "2222222222": 2} {
+		revisions = append(revisions, &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"controller-revision-hash": hash,
+				},
+				Name:      "agent-" + hash,
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					metav1.OwnerReference{
+						UID: (types.UID)("0001"),
+					},
+				},
+			},
+			Revision: revision,
+		})
+	}
+	pods := []*v1.Pod{}
+	for _, hash := range []string{"1111111111", "2222222222"} {
+		pods = append(pods, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"controller-revision-hash": hash,
+				},
+				Name:      "agent-" + hash,
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					metav1.OwnerReference{
+						UID: (types.UID)("0001"),
+					},
+				},
+			},
+		})
+	}
+
+	clientset := fake.NewSimpleClientset(raw, revisions[0], revisions[1], pods[0], pods[1])
+	client := &Client{
+		clientset: clientset,
+	}
+
+	got, err := client.listRolloutPods(NewDaemonSet("", raw))
+	if err != nil {
+		t.Errorf("got error: %s", err)
+		return
+	}
+
+	if len(got) != 1 {
+		t.Errorf("want: 1 pod, got: %d pods", len(got))
+		return
+	}
+
+	want := "agent-2222222222"
+	if got[0].Name() != want {
+		t.Errorf("want: %q, got: %q", want, got[0].Name())
+	}
+}
+
+func TestRolloutStarted(t *testing.T) {
+	deployment := func(generation, observedGeneration int64, revision string) *Deployment {
+		return NewDeployment("", &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"deployment.kubernetes.io/revision": revision,
+				},
+				Generation: generation,
+			},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: observedGeneration,
+			},
+		})
+	}
+	statefulSet := func(generation, observedGeneration int64, updateRevision string) *StatefulSet {
+		return NewStatefulSet("", &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Generation: generation,
+			},
+			Status: appsv1.StatefulSetStatus{
+				ObservedGeneration: observedGeneration,
+				UpdateRevision:     updateRevision,
+			},
+		})
+	}
+	daemonSet := func(generation, observedGeneration int64) *DaemonSet {
+		return NewDaemonSet("", &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Generation: generation,
+			},
+			Status: appsv1.DaemonSetStatus{
+				ObservedGeneration: observedGeneration,
+			},
+		})
+	}
+
+	testcases := []struct {
+		updated Workload
+		current Workload
+		want    bool
+	}{
+		{
+			updated: deployment(2, 1, "1"),
+			current: deployment(2, 1, "1"),
+			want:    false,
+		},
+		{
+			updated: deployment(2, 1, "1"),
+			current: deployment(2, 2, "1"),
+			want:    false,
+		},
+		{
+			updated: deployment(2, 1, "1"),
+			current: deployment(2, 2, "2"),
+			want:    true,
+		},
+		{
+			updated: deployment(1, 1, "1"),
+			current: deployment(1, 1, "1"),
+			want:    true,
+		},
+		{
+			updated: statefulSet(2, 1, "web-1111111111"),
+			current: statefulSet(2, 2, "web-1111111111"),
+			want:    false,
+		},
+		{
+			updated: statefulSet(2, 1, "web-1111111111"),
+			current: statefulSet(2, 2, "web-2222222222"),
+			want:    true,
+		},
+		{
+			updated: daemonSet(2, 1),
+			current: daemonSet(2, 1),
+			want:    false,
+		},
+		{
+			updated: daemonSet(2, 1),
+			current: daemonSet(2, 2),
+			want:    true,
+		},
+	}
+
+	for _, tc := range testcases {
+		if got := rolloutStarted(tc.updated, tc.current); got != tc.want {
+			t.Errorf("%s: want: %t, got: %t", WorkloadKey(tc.current), tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// StatefulSet represents the wrapper of Kubernetes StatefulSet
+type StatefulSet struct {
+	podTemplateObject
+	raw *appsv1.StatefulSet
+}
+
+// NewStatefulSet creates new StatefulSet object
+func NewStatefulSet(annotationPrefix string, raw *appsv1.StatefulSet) *StatefulSet {
+	return &StatefulSet{
+		podTemplateObject: newPodTemplateObject(annotationPrefix, KindStatefulSet, &raw.ObjectMeta, &raw.Spec.Template),
+		raw:               raw,
+	}
+}
+
+// RolloutStatus returns whether the latest rollout of StatefulSet has been completed,
+// and the message which describes the current progress
+func (s *StatefulSet) RolloutStatus() (bool, string, error) {
+	if s.raw.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return true, fmt.Sprintf("rollout status is not available for %s strategy", s.raw.Spec.UpdateStrategy.Type), nil
+	}
+
+	if s.raw.Status.ObservedGeneration == 0 || s.raw.Generation > s.raw.Status.ObservedGeneration {
+		return false, "waiting for statefulset spec update to be observed", nil
+	}
+
+	if s.raw.Spec.Replicas != nil && s.raw.Status.ReadyReplicas < *s.raw.Spec.Replicas {
+		return false, fmt.Sprintf("%d of %d pods are ready", s.raw.Status.ReadyReplicas, *s.raw.Spec.Replicas), nil
+	}
+
+	if ru := s.raw.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && s.raw.Spec.Replicas != nil {
+		if s.raw.Status.UpdatedReplicas < *s.raw.Spec.Replicas-*ru.Partition {
+			return false, fmt.Sprintf("%d out of %d new pods have been updated in partitioned rollout", s.raw.Status.UpdatedReplicas, *s.raw.Spec.Replicas-*ru.Partition), nil
+		}
+
+		return true, "partitioned rollout completed", nil
+	}
+
+	if s.raw.Status.UpdateRevision != s.raw.Status.CurrentRevision {
+		return false, fmt.Sprintf("%d pods are updated to revision %s", s.raw.Status.UpdatedReplicas, s.raw.Status.UpdateRevision), nil
+	}
+
+	return true, "successfully rolled out", nil
+}
+
+// UpdateRevision returns the name of ControllerRevision which Pods are being updated to
+func (s *StatefulSet) UpdateRevision() string {
+	return s.raw.Status.UpdateRevision
+}
@@ -0,0 +1,154 @@
+package kubernetes
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatefulSetIsDeployTarget(t *testing.T) {
+	testcases := []struct {
+		statefulSet *StatefulSet
+		expected    bool
+	}{
+		{
+			statefulSet: NewStatefulSet("", &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "worker",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"deploy-target": "true",
+					},
+				},
+			}),
+			expected: true,
+		},
+		{
+			statefulSet: NewStatefulSet("", &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "worker",
+					Namespace: "default",
+				},
+			}),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		got := tc.statefulSet.IsDeployTarget()
+		if got != tc.expected {
+			t.Errorf("expected: %t, got: %t", tc.expected, got)
+		}
+	}
+}
+
+func TestStatefulSetRolloutStatus(t *testing.T) {
+	replicas := int32(3)
+	partition := int32(2)
+
+	testcases := []struct {
+		spec     appsv1.StatefulSetSpec
+		status   appsv1.StatefulSetStatus
+		expected bool
+	}{
+		{
+			spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+					Type: appsv1.RollingUpdateStatefulSetStrategyType,
+				},
+			},
+			status: appsv1.StatefulSetStatus{
+				ObservedGeneration: 2,
+				ReadyReplicas:      3,
+				UpdatedReplicas:    3,
+				CurrentRevision:    "worker-2222222222",
+				UpdateRevision:     "worker-2222222222",
+			},
+			expected: true,
+		},
+		{
+			spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+					Type: appsv1.RollingUpdateStatefulSetStrategyType,
+				},
+			},
+			status: appsv1.StatefulSetStatus{
+				ObservedGeneration: 1,
+				ReadyReplicas:      3,
+				UpdatedReplicas:    3,
+				CurrentRevision:    "worker-1111111111",
+				UpdateRevision:     "worker-1111111111",
+			},
+			expected: false,
+		},
+		{
+			spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+					Type: appsv1.RollingUpdateStatefulSetStrategyType,
+				},
+			},
+			status: appsv1.StatefulSetStatus{
+				ObservedGeneration: 2,
+				ReadyReplicas:      3,
+				UpdatedReplicas:    1,
+				CurrentRevision:    "worker-1111111111",
+				UpdateRevision:     "worker-2222222222",
+			},
+			expected: false,
+		},
+		{
+			spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+					Type: appsv1.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
+						Partition: &partition,
+					},
+				},
+			},
+			status: appsv1.StatefulSetStatus{
+				ObservedGeneration: 2,
+				ReadyReplicas:      3,
+				UpdatedReplicas:    1,
+				CurrentRevision:    "worker-1111111111",
+				UpdateRevision:     "worker-2222222222",
+			},
+			expected: true,
+		},
+		{
+			spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+					Type: appsv1.OnDeleteStatefulSetStrategyType,
+				},
+			},
+			status:   appsv1.StatefulSetStatus{},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		statefulSet := NewStatefulSet("", &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "worker",
+				Namespace:  "default",
+				Generation: 2,
+			},
+			Spec:   tc.spec,
+			Status: tc.status,
+		})
+
+		got, _, err := statefulSet.RolloutStatus()
+		if err != nil {
+			t.Errorf("got error: %s", err)
+		}
+
+		if got != tc.expected {
+			t.Errorf("expected: %t, got: %t", tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package kubernetes
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds of Workload
+const (
+	KindDaemonSet   = "DaemonSet"
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+)
+
+var (
+	deployTargetAnnotationTrue = []string{"1", "true"}
+
+	boolAnnotationFalse = []string{"0", "false"}
+	boolAnnotationTrue  = []string{"1", "true"}
+)
+
+// Workload represents the Kubernetes resource which manages Pods with Pod template
+// (Deployment, StatefulSet, DaemonSet)
+type Workload interface {
+	Annotations() map[string]string
+	Containers() []*Container
+	ContainerImage(container string) string
+	DeployTargetContainers() ([]*Container, error)
+	ImagePullSecrets() []string
+	IsDeployTarget() bool
+	Kind() string
+	Labels() map[string]string
+	Name() string
+	Namespace() string
+	PinDigest() bool
+	PodLabels() map[string]string
+	Repository(container string) (string, error)
+	Repositories() (map[string]string, error)
+	RolloutStatus() (bool, string, error)
+	UID() string
+}
+
+// ParseKind returns the kind of Workload matched to the given name case-insensitively
+func ParseKind(name string) (string, error) {
+	for _, k := range []string{KindDaemonSet, KindDeployment, KindStatefulSet} {
+		if strings.EqualFold(name, k) {
+			return k, nil
+		}
+	}
+
+	return "", errors.Errorf("unsupported kind %q, must be one of %s, %s or %s", name, KindDaemonSet, KindDeployment, KindStatefulSet)
+}
+
+// WorkloadKey returns the unique key of Workload (or CronJob) in namespace
+func WorkloadKey(w interface {
+	Kind() string
+	Name() string
+}) string {
+	return w.Kind() + "/" + w.Name()
+}
+
+// podTemplateObject implements the common behavior of Workloads and CronJob, and is embedded in them
+type podTemplateObject struct {
+	annotationPrefix string
+	kind             string
+	meta             *metav1.ObjectMeta
+	template         *v1.PodTemplateSpec
+}
+
+func newPodTemplateObject(annotationPrefix, kind string, meta *metav1.ObjectMeta, template *v1.PodTemplateSpec) podTemplateObject {
+	return podTemplateObject{
+		annotationPrefix: annotationPrefix,
+		kind:             kind,
+		meta:             meta,
+		template:         template,
+	}
+}
+
+// Annotations returns the annotations of object
+func (o *podTemplateObject) Annotations() map[string]string {
+	return o.meta.Annotations
+}
+
+// Containers returns the containers inside Pod template
+func (o *podTemplateObject) Containers() []*Container {
+	containers := []*Container{}
+
+	for i := range o.template.Spec.Containers {
+		containers = append(containers, NewContainer(&o.template.Spec.Containers[i]))
+	}
+
+	return containers
+}
+
+// ContainerImage returns image name of the given container
+func (o *podTemplateObject) ContainerImage(container string) string {
+	for _, c := range o.Containers() {
+		if c.Name() == container {
+			return c.Image()
+		}
+	}
+
+	return ""
+}
+
+// DeployTargetContainers returns the containers
+// - specified in `deploy-target-container` annotation (comma-separated list)
+func (o *podTemplateObject) DeployTargetContainers() ([]*Container, error) {
+	names := listAnnotation(o.meta.Annotations, o.annotationPrefix+deployTargetContainerAnnotation)
+	if names == nil {
+		return []*Container{}, errors.Errorf("annotation %q does not exist in %s %q", o.annotationPrefix+deployTargetContainerAnnotation, o.kind, o.meta.Name)
+	}
+
+	if len(names) == 0 {
+		return []*Container{}, errors.Errorf("annotation %q is empty in %s %q", o.annotationPrefix+deployTargetContainerAnnotation, o.kind, o.meta.Name)
+	}
+
+	containers := make([]*Container, 0, len(names))
+
+	for _, n := range names {
+		var container *Container
+
+		for _, c := range o.Containers() {
+			if c.Name() == n {
+				container = c
+				break
+			}
+		}
+
+		if container == nil {
+			return []*Container{}, errors.Errorf("container %q does not exist in %s %q", n, o.kind, o.meta.Name)
+		}
+
+		containers = append(containers, container)
+	}
+
+	return containers, nil
+}
+
+// ImagePullSecrets returns the names of imagePullSecrets in Pod template
+func (o *podTemplateObject) ImagePullSecrets() []string {
+	secrets := []string{}
+
+	for _, s := range o.template.Spec.ImagePullSecrets {
+		secrets = append(secrets, s.Name)
+	}
+
+	return secrets
+}
+
+// IsDeployTarget returns whether this object is deploy target or not
+// - has `deploy-target: 1` or `deploy-target: true` annotation
+func (o *podTemplateObject) IsDeployTarget() bool {
+	for _, v := range deployTargetAnnotationTrue {
+		if o.meta.Annotations[o.annotationPrefix+deployTargetAnnotation] == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Kind returns the kind of object
+func (o *podTemplateObject) Kind() string {
+	return o.kind
+}
+
+// Labels returns the labels of object
+func (o *podTemplateObject) Labels() map[string]string {
+	return o.meta.Labels
+}
+
+// Name returns the name of object
+func (o *podTemplateObject) Name() string {
+	return o.meta.Name
+}
+
+// Namespace returns the namespace of object
+func (o *podTemplateObject) Namespace() string {
+	return o.meta.Namespace
+}
+
+// PinDigest returns whether the image should be pinned by digest
+// - has `pin-digest: 1` or `pin-digest: true` annotation
+func (o *podTemplateObject) PinDigest() bool {
+	b := boolAnnotation(o.meta.Annotations, o.annotationPrefix+pinDigestAnnotation)
+	return b != nil && *b
+}
+
+// PodLabels returns the labels of Pod template
+func (o *podTemplateObject) PodLabels() map[string]string {
+	return o.template.Labels
+}
+
+// Repository returns the repository of the given container attached by 'github' annotation
+func (o *podTemplateObject) Repository(container string) (string, error) {
+	repos, err := o.Repositories()
+	if err != nil {
+		return "", err
+	}
+
+	repo, ok := repos[container]
+	if !ok {
+		return "", errors.Errorf("GitHub repository for container %q not found in %s %q", container, o.kind, o.meta.Name)
+	}
+
+	return repo, nil
+}
+
+// Repositories returns the reportories attached by 'github' annotation
+func (o *podTemplateObject) Repositories() (map[string]string, error) {
+	v, ok := o.meta.Annotations[o.annotationPrefix+githubAnnotation]
+	if !ok {
+		return map[string]string{}, errors.Errorf("annotation %q not found in %s %q", o.annotationPrefix+githubAnnotation, o.kind, o.meta.Name)
+	}
+
+	repos := map[string]string{}
+
+	for _, f := range strings.Split(v, ",") {
+		ss := strings.Split(f, "=")
+		if len(ss) != 2 {
+			return map[string]string{}, errors.Errorf(`invalid annotation %q value %q, must be "container=owner/repo"`, o.annotationPrefix+githubAnnotation, f)
+		}
+		repos[ss[0]] = ss[1]
+	}
+
+	return repos, nil
+}
+
+// UID returns the UID of object
+func (o *podTemplateObject) UID() string {
+	return string(o.meta.UID)
+}
+
+// boolAnnotation returns the boolean value of annotation
+// nil is returned if the annotation is not set
+func boolAnnotation(annotations map[string]string, key string) *bool {
+	v := annotations[key]
+
+	for _, t := range boolAnnotationTrue {
+		if v == t {
+			b := true
+			return &b
+		}
+	}
+
+	for _, f := range boolAnnotationFalse {
+		if v == f {
+			b := false
+			return &b
+		}
+	}
+
+	return nil
+}
+
+// listAnnotation returns the comma-separated values of annotation
+// nil is returned if the annotation is not set
+func listAnnotation(annotations map[string]string, key string) []string {
+	v, ok := annotations[key]
+	if !ok {
+		return nil
+	}
+
+	values := []string{}
+
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			values = append(values, s)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,57 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseKind(t *testing.T) {
+	testcases := []struct {
+		name      string
+		expected  string
+		expectErr bool
+		errMsg    string
+	}{
+		{
+			name:     "Deployment",
+			expected: KindDeployment,
+		},
+		{
+			name:     "statefulset",
+			expected: KindStatefulSet,
+		},
+		{
+			name:     "DAEMONSET",
+			expected: KindDaemonSet,
+		},
+		{
+			name:      "CronJob",
+			expectErr: true,
+			errMsg:    `unsupported kind "CronJob"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		got, err := ParseKind(tc.name)
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+				continue
+			}
+
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.errMsg)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+				continue
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected: %q, got: %q", tc.expected, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRegistry = "registry-1.docker.io"
+	requestTimeout  = 30 * time.Second
+)
+
+var (
+	// manifestMediaTypes are the manifest formats accepted in manifest request
+	// Registry returns 404 if the manifest exists only in other formats
+	manifestMediaTypes = []string{
+		"application/vnd.docker.distribution.manifest.v2+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.docker.distribution.manifest.v1+prettyjws",
+	}
+
+	// dockerHubHosts are the hostnames which refer Docker Hub in Docker config
+	dockerHubHosts = []string{"index.docker.io", "docker.io", defaultRegistry}
+)
+
+// Credential represents the credential of Docker registry
+type Credential struct {
+	Username string
+	Password string
+}
+
+// StatusError represents the unexpected HTTP status returned from registry or its authorization server
+type StatusError struct {
+	StatusCode int
+	Source     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s", e.StatusCode, e.Source)
+}
+
+// IsRetryable returns whether the request causing the given error may succeed later
+// Network errors, 5xx and 429 are retryable. 401, 403 and 404 are also retryable,
+// because some registries return them until the repository is created by the first push
+func IsRetryable(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case *StatusError:
+		switch e.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests:
+			return true
+		}
+
+		return e.StatusCode >= http.StatusInternalServerError
+	case net.Error:
+		return true
+	}
+
+	return false
+}
+
+// Client represents the client of Docker Registry HTTP API v2
+type Client struct {
+	credentials map[string]Credential
+	httpClient  *http.Client
+}
+
+// NewClient creates new Client object
+// credentials are keyed by registry hostname
+func NewClient(credentials map[string]Credential) *Client {
+	return &Client{
+		credentials: credentials,
+		httpClient: &http.Client{
+			Timeout: requestTimeout,
+		},
+	}
+}
+
+// ManifestDigest returns the digest of manifest of the given reference (tag or digest) in repository
+// Empty registry means Docker Hub
+func (c *Client) ManifestDigest(registry, repository, reference string) (string, error) {
+	resp, err := c.headManifest(registry, repository, reference)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errors.Errorf("manifest %s:%s not found", repository, reference)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{StatusCode: resp.StatusCode, Source: "registry"}
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", errors.New("registry did not return Docker-Content-Digest header")
+	}
+
+	return digest, nil
+}
+
+// ManifestExists returns whether the manifest of the given reference (tag or digest) exists in repository
+// Empty registry means Docker Hub
+func (c *Client) ManifestExists(registry, repository, reference string) (bool, error) {
+	resp, err := c.headManifest(registry, repository, reference)
+	if err != nil {
+		return false, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+
+	return false, &StatusError{StatusCode: resp.StatusCode, Source: "registry"}
+}
+
+// ParseDockerConfig returns the credentials in Docker config
+// Both `.dockerconfigjson` and legacy `.dockercfg` format are accepted
+func ParseDockerConfig(data []byte) (map[string]Credential, error) {
+	type entry struct {
+		Auth     string `json:"auth"`
+		Password string `json:"password"`
+		Username string `json:"username"`
+	}
+
+	var config struct {
+		Auths map[string]entry `json:"auths"`
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return map[string]Credential{}, errors.Wrap(err, "failed to parse Docker config")
+	}
+
+	if config.Auths == nil {
+		if err := json.Unmarshal(data, &config.Auths); err != nil {
+			return map[string]Credential{}, errors.Wrap(err, "failed to parse Docker config")
+		}
+	}
+
+	credentials := map[string]Credential{}
+
+	for k, e := range config.Auths {
+		cred := Credential{
+			Username: e.Username,
+			Password: e.Password,
+		}
+
+		if e.Auth != "" {
+			b, err := base64.StdEncoding.DecodeString(e.Auth)
+			if err != nil {
+				return map[string]Credential{}, errors.Wrapf(err, "failed to decode auth of %q", k)
+			}
+
+			ss := strings.SplitN(string(b), ":", 2)
+			if len(ss) != 2 {
+				return map[string]Credential{}, errors.Errorf("invalid auth of %q, must be \"username:password\"", k)
+			}
+
+			cred.Username, cred.Password = ss[0], ss[1]
+		}
+
+		credentials[normalizeHost(k)] = cred
+	}
+
+	return credentials, nil
+}
+
+// headManifest sends HEAD request of manifest, with authorization if registry requires
+func (c *Client) headManifest(registry, repository, reference string) (*http.Response, error) {
+	host, repository := normalizeRepository(registry, repository)
+	u := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme(host), host, repository, reference)
+
+	resp, err := c.requestManifest(u, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		authorization, err := c.authorize(host, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to authorize to registry %s", host)
+		}
+
+		return c.requestManifest(u, authorization)
+	}
+
+	return resp, nil
+}
+
+// authorize returns the value of Authorization header which meets the given challenge
+func (c *Client) authorize(host, challenge string) (string, error) {
+	authScheme, params := parseChallenge(challenge)
+	cred, hasCred := c.credentials[host]
+
+	switch strings.ToLower(authScheme) {
+	case "basic":
+		if !hasCred {
+			return "", errors.New("credential is required")
+		}
+
+		return "Basic " + basicAuth(cred), nil
+	case "bearer":
+		token, err := c.requestToken(params, cred, hasCred)
+		if err != nil {
+			return "", err
+		}
+
+		return "Bearer " + token, nil
+	}
+
+	return "", errors.Errorf("unsupported authentication scheme %q", authScheme)
+}
+
+// requestManifest sends HEAD request of manifest
+func (c *Client) requestManifest(u, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to request %s", u)
+	}
+	resp.Body.Close()
+
+	return resp, nil
+}
+
+// requestToken retrieves bearer token from the authorization server given in challenge
+func (c *Client) requestToken(params map[string]string, cred Credential, hasCred bool) (string, error) {
+	realm, ok := params["realm"]
+	if !ok {
+		return "", errors.New("realm is not given in challenge")
+	}
+
+	u, err := url.Parse(realm)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid realm %q", realm)
+	}
+
+	q := u.Query()
+
+	for _, k := range []string{"service", "scope"} {
+		if v, ok := params[k]; ok {
+			q.Set(k, v)
+		}
+	}
+
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create token request")
+	}
+
+	if hasCred {
+		req.SetBasicAuth(cred.Username, cred.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to request token to %s", realm)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{StatusCode: resp.StatusCode, Source: realm}
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		Token       string `json:"token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", errors.Wrap(err, "failed to decode token response")
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+
+	return "", errors.New("no token in token response")
+}
+
+func basicAuth(cred Credential) string {
+	return base64.StdEncoding.EncodeToString([]byte(cred.Username + ":" + cred.Password))
+}
+
+// normalizeHost extracts hostname from the key of Docker config
+// e.g. `https://index.docker.io/v1/` -> `registry-1.docker.io`
+func normalizeHost(key string) string {
+	host := key
+
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+
+	for _, h := range dockerHubHosts {
+		if host == h {
+			return defaultRegistry
+		}
+	}
+
+	return host
+}
+
+// normalizeRepository fills Docker Hub defaults
+// e.g. ("", "nginx") -> ("registry-1.docker.io", "library/nginx")
+func normalizeRepository(registry, repository string) (string, string) {
+	if registry == "" {
+		registry = defaultRegistry
+	}
+
+	registry = normalizeHost(registry)
+
+	if registry == defaultRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	return registry, repository
+}
+
+// parseChallenge parses WWW-Authenticate header
+// e.g. `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+
+	ss := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(ss) < 2 {
+		return ss[0], params
+	}
+
+	rest := ss[1]
+
+	for rest != "" {
+		i := strings.Index(rest, "=")
+		if i < 0 {
+			break
+		}
+
+		key := strings.ToLower(strings.TrimSpace(rest[:i]))
+		rest = rest[i+1:]
+
+		var value string
+
+		if strings.HasPrefix(rest, `"`) {
+			j := strings.Index(rest[1:], `"`)
+			if j < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:j+1], rest[j+2:]
+			}
+		} else {
+			j := strings.Index(rest, ",")
+			if j < 0 {
+				value, rest = rest, ""
+			} else {
+				value, rest = rest[:j], rest[j:]
+			}
+		}
+
+		params[key] = value
+		rest = strings.TrimLeft(rest, ", ")
+	}
+
+	return ss[0], params
+}
+
+// scheme returns the URL scheme of registry
+// Registry on localhost is accessed via HTTP like Docker does
+func scheme(host string) string {
+	hostname := host
+
+	if i := strings.LastIndex(hostname, ":"); i >= 0 {
+		hostname = hostname[:i]
+	}
+
+	if hostname == "localhost" || hostname == "127.0.0.1" {
+		return "http"
+	}
+
+	return "https"
+}
@@ -0,0 +1,273 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestManifestExists(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "dtan4" || p != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Query().Get("scope") != "repository:dtan4/bearer:pull" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		fmt.Fprint(w, `{"token":"t0ken"}`)
+	})
+
+	mux.HandleFunc("/v2/dtan4/public/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/v1") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	mux.HandleFunc("/v2/dtan4/basic/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "dtan4" || p != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/v2/dtan4/bearer/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:dtan4/bearer:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/v2/dtan4/unavailable/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	credentials := map[string]Credential{
+		host: Credential{
+			Username: "dtan4",
+			Password: "secret",
+		},
+	}
+
+	testcases := []struct {
+		credentials map[string]Credential
+		repository  string
+		reference   string
+		expectErr   bool
+		expected    bool
+		errMsg      string
+		retryable   bool
+	}{
+		{
+			credentials: map[string]Credential{},
+			repository:  "dtan4/public",
+			reference:   "v1",
+			expectErr:   false,
+			expected:    true,
+		},
+		{
+			credentials: map[string]Credential{},
+			repository:  "dtan4/public",
+			reference:   "v2",
+			expectErr:   false,
+			expected:    false,
+		},
+		{
+			credentials: credentials,
+			repository:  "dtan4/basic",
+			reference:   "v1",
+			expectErr:   false,
+			expected:    true,
+		},
+		{
+			credentials: map[string]Credential{},
+			repository:  "dtan4/basic",
+			reference:   "v1",
+			expectErr:   true,
+			errMsg:      "credential is required",
+		},
+		{
+			credentials: credentials,
+			repository:  "dtan4/bearer",
+			reference:   "sha256:0123456789abcdef",
+			expectErr:   false,
+			expected:    true,
+		},
+		{
+			credentials: map[string]Credential{},
+			repository:  "dtan4/bearer",
+			reference:   "v1",
+			expectErr:   true,
+			errMsg:      "unexpected status 401",
+			retryable:   true,
+		},
+		{
+			credentials: map[string]Credential{},
+			repository:  "dtan4/unavailable",
+			reference:   "v1",
+			expectErr:   true,
+			errMsg:      "unexpected status 503",
+			retryable:   true,
+		},
+	}
+
+	for _, tc := range testcases {
+		client := NewClient(tc.credentials)
+
+		got, err := client.ManifestExists(host, tc.repository, tc.reference)
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+				continue
+			}
+
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.errMsg)
+			}
+
+			if IsRetryable(err) != tc.retryable {
+				t.Errorf("expected retryable: %t, got: %t", tc.retryable, IsRetryable(err))
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+				continue
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected: %t, got: %t", tc.expected, got)
+			}
+		}
+	}
+}
+
+func TestManifestDigest(t *testing.T) {
+	digest := "sha256:6bf1d26d1b2dba22b4e2bd8a6a5b1d4fce8e0d7e6f9a4d7e2a3c1b0f9e8d7c6b"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/dtan4/app/manifests/v1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	client := NewClient(map[string]Credential{})
+
+	got, err := client.ManifestDigest(host, "dtan4/app", "v1")
+	if err != nil {
+		t.Errorf("got error: %s", err)
+	}
+
+	if got != digest {
+		t.Errorf("expected: %q, got: %q", digest, got)
+	}
+
+	if _, err := client.ManifestDigest(host, "dtan4/app", "v2"); err == nil {
+		t.Errorf("got no error")
+	}
+}
+
+func TestParseDockerConfig(t *testing.T) {
+	testcases := []struct {
+		data      string
+		expectErr bool
+		expected  map[string]Credential
+	}{
+		{
+			data: `{"auths":{"https://index.docker.io/v1/":{"auth":"ZHRhbjQ6c2VjcmV0"},"quay.io":{"username":"dtan4","password":"p@ss"}}}`,
+			expected: map[string]Credential{
+				"registry-1.docker.io": Credential{
+					Username: "dtan4",
+					Password: "secret",
+				},
+				"quay.io": Credential{
+					Username: "dtan4",
+					Password: "p@ss",
+				},
+			},
+		},
+		{
+			data: `{"localhost:5000":{"auth":"ZHRhbjQ6c2VjcmV0"}}`,
+			expected: map[string]Credential{
+				"localhost:5000": Credential{
+					Username: "dtan4",
+					Password: "secret",
+				},
+			},
+		},
+		{
+			data:      `{"auths":{"quay.io":{"auth":"invalid"}}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		got, err := ParseDockerConfig([]byte(tc.data))
+
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("got no error")
+			}
+		} else {
+			if err != nil {
+				t.Errorf("got error: %s", err)
+				continue
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected: %#v, got: %#v", tc.expected, got)
+			}
+		}
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	challenge := `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:dtan4/app:pull,push"`
+
+	gotScheme, gotParams := parseChallenge(challenge)
+
+	if gotScheme != "Bearer" {
+		t.Errorf("expected: %q, got: %q", "Bearer", gotScheme)
+	}
+
+	expected := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:dtan4/app:pull,push",
+	}
+	if !reflect.DeepEqual(gotParams, expected) {
+		t.Errorf("expected: %q, got: %q", expected, gotParams)
+	}
+}